@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(breachIndexCmd)
+}
+
+var breachIndexCmd = &cobra.Command{
+	Use:   "breach-index SOURCE INDEX",
+	Short: "Build a compact index of a Pwned Passwords dataset",
+	Long: `
+Breach-index converts a locally downloaded Have I Been Pwned "Pwned Passwords" dataset into a
+compact binary index, which is about half the size of the text files and answers lookups with a
+few reads. SOURCE is either a directory of range files (such as 21BD1.txt) or the SHA-1 hash file
+ordered by hash. The index is used with the --breach-db flag of gofee and gofee check.
+`,
+	Example: `
+gofee breach-index pwnedpasswords/ pwned-passwords.idx
+gofee breach-index pwned-passwords-sha1-ordered-by-hash-v8.txt pwned-passwords.idx
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		n, err := gofee.BuildBreachIndex(args[0], args[1])
+		if err != nil {
+			log.Fatalf("Error building breach index: %v", err)
+		}
+		fmt.Printf("Indexed %d hashes into %s\n", n, args[1])
+	},
+}
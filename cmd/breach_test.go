@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeBreachHashFile writes a Pwned Passwords hash file of the passwords and returns its path.
+func writeBreachHashFile(t *testing.T, passwords ...string) string {
+	t.Helper()
+
+	var lines []string
+	for _, password := range passwords {
+		lines = append(lines, fmt.Sprintf("%X:42\n", sha1.Sum([]byte(password))))
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "hashes.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBreachIndexCmd(t *testing.T) {
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	index := filepath.Join(t.TempDir(), "pwned.idx")
+	rootCmd.SetArgs([]string{"breach-index", writeBreachHashFile(t, "password", "hunter2"), index})
+
+	output, err := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("error executing breach-index: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	if !strings.Contains(output, "Indexed 2 hashes") {
+		t.Errorf("expected output to report 2 hashes, but got %q", output)
+	}
+	if _, err := os.Stat(index); err != nil {
+		t.Errorf("expected the index to be written: %v", err)
+	}
+}
+
+func TestCheckCmdBreachDB(t *testing.T) {
+	db := writeBreachHashFile(t, "hunter2")
+
+	output := executeCheck(t, "hunter2\n", "--breach-db", db)
+	if !strings.Contains(output, "Breaches: found 42 times") {
+		t.Errorf("expected output to report the breach, but got %q", output)
+	}
+
+	output = executeCheck(t, "hunter3\n", "--breach-db", db, "--output", "json")
+	if !strings.Contains(output, `"breaches":0`) {
+		t.Errorf("expected output to report no breaches, but got %q", output)
+	}
+}
+
+func TestRootCmdWithBreachDB(t *testing.T) {
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	// Every PIN but 7 and 9 is breached.
+	var breached []string
+	for _, pin := range "01234568" {
+		breached = append(breached, string(pin))
+	}
+	rootCmd.SetArgs([]string{"--type", "pin", "--length", "1", "--count", "5", "--output", "plain", "--breach-db", writeBreachHashFile(t, breached...)})
+
+	output, err := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("error executing rootCmd: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	pins := strings.Fields(output)
+	if len(pins) != 5 {
+		t.Fatalf("expected 5 PINs, but got %q", output)
+	}
+	for _, pin := range pins {
+		if pin != "7" && pin != "9" {
+			t.Errorf("expected only unbreached PINs, but got %q", pin)
+		}
+	}
+}
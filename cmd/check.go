@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Options for the check command
+var checkOptions struct {
+	userInputs []string
+	breachDB   string
+	output     string
+	noColor    bool
+}
+
+// Names of the scores of gofee.StrengthEstimate.
+var scoreNames = []string{"too guessable", "very guessable", "somewhat guessable", "safely unguessable", "very unguessable"}
+
+// jsonEstimate is the JSON representation of a strength estimate. The password is not included.
+type jsonEstimate struct {
+	Score        int               `json:"score"`
+	Strength     string            `json:"strength"`
+	Guesses      float64           `json:"guesses"`
+	GuessesLog10 float64           `json:"guesses_log10"`
+	CrackTimes   []gofee.CrackTime `json:"crack_times"`
+	Warning      string            `json:"warning"`
+	Suggestions  []string          `json:"suggestions"`
+	Patterns     []string          `json:"patterns"`
+	Breaches     *int              `json:"breaches,omitempty"`
+}
+
+func init() {
+	checkCmd.Flags().StringSliceVar(&checkOptions.userInputs, "user-input", nil, "words known about the user, such as names or e-mail addresses")
+	checkCmd.Flags().StringVar(&checkOptions.breachDB, "breach-db", "", "look the password up in a local Pwned Passwords dataset or index")
+	checkCmd.Flags().StringVarP(&checkOptions.output, "output", "o", formatText, "output format ("+formatText+", "+formatJSON+")")
+	checkCmd.Flags().BoolVar(&checkOptions.noColor, "no-color", false, "disable colored output")
+
+	rootCmd.AddCommand(checkCmd)
+}
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Estimate the strength of a password read from stdin",
+	Long: `
+Check estimates how hard a password is to guess. It searches the password for dictionary words,
+keyboard walks, repeats, sequences, dates and l33t substitutions, and reports a score from 0 to 4,
+estimated crack times and advice for a stronger password. With --breach-db, the password is also
+looked up in a locally downloaded Have I Been Pwned dataset (see gofee breach-index).
+
+The password is read from stdin and never accepted as an argument, so it does not end up in the
+shell history or the process list. On a terminal, the password is prompted for without echo.
+`,
+	Example: `
+gofee check
+echo 'correct horse battery staple' | gofee check
+gofee check --user-input tim --user-input tim@example.com --output json < password.txt
+gofee check --breach-db pwned-passwords.idx
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if checkOptions.output != formatText && checkOptions.output != formatJSON {
+			log.Fatalf("Error: unknown output format %q (supported: %s, %s)", checkOptions.output, formatText, formatJSON)
+		}
+
+		// Open the breach database before reading the password, so a wrong path is reported first.
+		var db *gofee.LocalBreachDB
+		if checkOptions.breachDB != "" {
+			var err error
+			db, err = gofee.OpenBreachDB(checkOptions.breachDB)
+			if err != nil {
+				log.Fatalf("Error opening breach database: %v", err)
+			}
+			defer db.Close()
+		}
+
+		password, err := readPassword(cmd.InOrStdin(), os.Stderr, "Password")
+		if err != nil {
+			log.Fatalf("Error reading password: %v", err)
+		}
+
+		if checkOptions.noColor || !isTerminal(os.Stdout) {
+			color.NoColor = true
+		}
+
+		estimate := gofee.EstimateStrength(password, checkOptions.userInputs...)
+
+		var breaches *int
+		if db != nil {
+			n, err := db.Breaches(password)
+			if err != nil {
+				log.Fatalf("Error checking password for breaches: %v", err)
+			}
+			breaches = &n
+		}
+
+		if err := writeEstimate(os.Stdout, checkOptions.output, estimate, breaches); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	},
+}
+
+// readPassword reads a single password from in. On a terminal the password is prompted for on
+// prompt with the label without echo, otherwise the first line of in is used.
+func readPassword(in io.Reader, prompt io.Writer, label string) (string, error) {
+	if f, ok := in.(*os.File); ok && isTerminal(f) {
+		fmt.Fprintf(prompt, "%s: ", label)
+		password, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(prompt)
+		return string(password), err
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if err != nil && line == "" {
+		return "", errors.New("no password on stdin")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// writeEstimate writes the strength estimate in the text or json format.
+// breaches is the number of times the password was breached, or nil if it was not looked up.
+func writeEstimate(w io.Writer, format string, estimate gofee.StrengthEstimate, breaches *int) error {
+	if format == formatJSON {
+		return json.NewEncoder(w).Encode(newJSONEstimate(estimate, breaches))
+	}
+
+	scoreColor := color.New(color.FgRed)
+	if estimate.Score >= gofee.ScoreSafelyUnguessable {
+		scoreColor = color.New(color.FgGreen)
+	} else if estimate.Score == gofee.ScoreSomewhatGuessable {
+		scoreColor = color.New(color.FgYellow)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Score: %s\n", scoreColor.Sprintf("%d/4 (%s)", estimate.Score, scoreNames[estimate.Score]))
+	fmt.Fprintf(&b, "Guesses: 10^%.1f\n", estimate.GuessesLog10)
+	fmt.Fprintln(&b, "Crack times:")
+	for _, crackTime := range estimate.CrackTimes {
+		fmt.Fprintf(&b, "  %s: %s\n", crackTime.Scenario, crackTime.Display)
+	}
+	if breaches != nil && *breaches > 0 {
+		fmt.Fprintf(&b, "Breaches: %s\n", color.RedString("found %d times in known data breaches, do not use this password", *breaches))
+	} else if breaches != nil {
+		fmt.Fprintf(&b, "Breaches: %s\n", color.GreenString("not found in known data breaches"))
+	}
+	if estimate.Feedback.Warning != "" {
+		fmt.Fprintf(&b, "Warning: %s\n", color.YellowString(estimate.Feedback.Warning))
+	}
+	if len(estimate.Feedback.Suggestions) > 0 {
+		fmt.Fprintln(&b, "Suggestions:")
+		for _, suggestion := range estimate.Feedback.Suggestions {
+			fmt.Fprintf(&b, "  - %s\n", suggestion)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// newJSONEstimate converts a strength estimate into its JSON representation.
+func newJSONEstimate(estimate gofee.StrengthEstimate, breaches *int) jsonEstimate {
+	patterns := make([]string, len(estimate.Sequence))
+	for i, match := range estimate.Sequence {
+		patterns[i] = match.Pattern
+	}
+
+	suggestions := estimate.Feedback.Suggestions
+	if suggestions == nil {
+		suggestions = []string{}
+	}
+
+	return jsonEstimate{
+		Score:        estimate.Score,
+		Strength:     scoreNames[estimate.Score],
+		Guesses:      estimate.Guesses,
+		GuessesLog10: estimate.GuessesLog10,
+		CrackTimes:   estimate.CrackTimes,
+		Warning:      estimate.Feedback.Warning,
+		Suggestions:  suggestions,
+		Patterns:     patterns,
+		Breaches:     breaches,
+	}
+}
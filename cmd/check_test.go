@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+)
+
+// executeCheck runs the check command with the given stdin and arguments and returns its output.
+func executeCheck(t *testing.T, stdin string, args ...string) string {
+	t.Helper()
+	defer resetFlags(t)
+	defer rootCmd.SetIn(nil)
+	defer rootCmd.SetArgs(nil)
+
+	rootCmd.SetIn(strings.NewReader(stdin))
+	rootCmd.SetArgs(append([]string{"check"}, args...))
+
+	output, err := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("error executing check: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+	return output
+}
+
+func TestCheckCmd(t *testing.T) {
+	output := executeCheck(t, "password\n")
+
+	for _, want := range []string{"Score: 0/4 (too guessable)", "Crack times:", "Warning: This is a top-10 common password."} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, but got %q", want, output)
+		}
+	}
+	if strings.Contains(output, "password\n") {
+		t.Errorf("expected output not to contain the password, but got %q", output)
+	}
+}
+
+func TestCheckCmdJSON(t *testing.T) {
+	output := executeCheck(t, "Wehrlegofee", "--output", "json", "--user-input", "gofee,wehrle")
+
+	var got jsonEstimate
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("failed to parse %q: %v", output, err)
+	}
+
+	want := gofee.EstimateStrength("Wehrlegofee", "gofee", "wehrle")
+	if got.Score != want.Score || got.Guesses != want.Guesses {
+		t.Errorf("expected score %d with %v guesses, but got %+v", want.Score, want.Guesses, got)
+	}
+	if len(got.CrackTimes) != len(want.CrackTimes) || got.Patterns[0] != gofee.PatternDictionary {
+		t.Errorf("unexpected estimate %+v", got)
+	}
+}
+
+func TestReadPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "First line", input: "secret one\nsecond line\n", want: "secret one"},
+		{name: "Windows line ending", input: "secret\r\n", want: "secret"},
+		{name: "No newline", input: "  secret  ", want: "  secret  "},
+		{name: "Empty line", input: "\n", want: ""},
+		{name: "Empty input", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prompt bytes.Buffer
+			got, err := readPassword(strings.NewReader(tt.input), &prompt, "Password")
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("readPassword() = %q, want %q", got, tt.want)
+			}
+			if prompt.Len() != 0 {
+				t.Errorf("readPassword() prompted %q without a terminal", prompt.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/fatih/color"
+)
+
+// defaultClipTimeout is the time after which a copied password is cleared from the clipboard.
+const defaultClipTimeout = 45 * time.Second
+
+// errPasteUnsupported is returned by clipboards which cannot be read.
+var errPasteUnsupported = errors.New("reading the clipboard is not supported")
+
+// clipboard is the system clipboard.
+type clipboard interface {
+	// Copy places the text on the clipboard.
+	Copy(text string) error
+	// Paste returns the text on the clipboard.
+	Paste() (string, error)
+}
+
+// commandClipboard is a clipboard accessed by external commands, such as xclip.
+type commandClipboard struct {
+	copyArgs  []string
+	pasteArgs []string
+}
+
+// Copy runs the copy command with the text on its standard input.
+func (c commandClipboard) Copy(text string) error {
+	cmd := exec.Command(c.copyArgs[0], c.copyArgs[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running %s: %v", c.copyArgs[0], err)
+	}
+	return nil
+}
+
+// Paste returns the output of the paste command.
+func (c commandClipboard) Paste() (string, error) {
+	out, err := exec.Command(c.pasteArgs[0], c.pasteArgs[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("error running %s: %v", c.pasteArgs[0], err)
+	}
+	return string(out), nil
+}
+
+// osc52Clipboard sets the clipboard of the terminal with the OSC 52 escape sequence, which also
+// works over SSH. The clipboard cannot be read, as most terminals do not answer queries.
+type osc52Clipboard struct {
+	w io.Writer
+}
+
+// Copy writes the escape sequence setting the clipboard to the text.
+func (c osc52Clipboard) Copy(text string) error {
+	_, err := fmt.Fprintf(c.w, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err
+}
+
+// Paste returns errPasteUnsupported.
+func (c osc52Clipboard) Paste() (string, error) {
+	return "", errPasteUnsupported
+}
+
+// clipboardCommands are the clipboard commands in the order they are tried, with the environment
+// variable that must be set for them to work.
+var clipboardCommands = []struct {
+	env string
+	commandClipboard
+}{
+	{"WAYLAND_DISPLAY", commandClipboard{[]string{"wl-copy"}, []string{"wl-paste", "--no-newline"}}},
+	{"DISPLAY", commandClipboard{[]string{"xclip", "-selection", "clipboard"}, []string{"xclip", "-selection", "clipboard", "-o"}}},
+	{"DISPLAY", commandClipboard{[]string{"xsel", "--clipboard", "--input"}, []string{"xsel", "--clipboard", "--output"}}},
+	{"", commandClipboard{[]string{"pbcopy"}, []string{"pbpaste"}}},
+}
+
+// findClipboard returns the first clipboard command which is installed and usable in the
+// environment. Without one, it falls back to OSC 52 on the terminal if there is one.
+func findClipboard(getenv func(string) string, lookPath func(string) (string, error), terminal io.Writer) (clipboard, error) {
+	for _, c := range clipboardCommands {
+		if c.env != "" && getenv(c.env) == "" {
+			continue
+		}
+		if _, err := lookPath(c.copyArgs[0]); err != nil {
+			continue
+		}
+		if _, err := lookPath(c.pasteArgs[0]); err != nil {
+			continue
+		}
+		return c.commandClipboard, nil
+	}
+
+	if terminal != nil {
+		return osc52Clipboard{w: terminal}, nil
+	}
+	return nil, fmt.Errorf("no clipboard found (install wl-clipboard, xclip or xsel, or use a terminal supporting OSC 52)")
+}
+
+// openClipboard returns the clipboard of the system. It is replaced by a fake in tests.
+var openClipboard = func() (clipboard, error) {
+	var terminal io.Writer
+	if isTerminal(os.Stdout) {
+		terminal = os.Stdout
+	}
+	return findClipboard(os.Getenv, exec.LookPath, terminal)
+}
+
+// waitClipboard waits for the timeout or until the user interrupts, so Ctrl+C clears the
+// clipboard early. It is replaced in tests.
+var waitClipboard = func(timeout time.Duration) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	select {
+	case <-time.After(timeout):
+	case <-interrupt:
+	}
+}
+
+// clearClipboard clears the clipboard if it still holds the text, so anything copied in the
+// meantime is kept. Clipboards which cannot be read are cleared unconditionally.
+// It reports whether the clipboard was cleared.
+func clearClipboard(c clipboard, text string) (bool, error) {
+	current, err := c.Paste()
+	if err != nil && !errors.Is(err, errPasteUnsupported) {
+		return false, err
+	}
+	if err == nil && current != text {
+		return false, nil
+	}
+	return true, c.Copy("")
+}
+
+// copyPassword generates a single password, copies it to the clipboard instead of printing it,
+// and clears the clipboard after the timeout. A timeout of 0 keeps the password on the clipboard.
+func copyPassword(length int, config gofee.PasswordConfig, timeout time.Duration) error {
+	c, err := openClipboard()
+	if err != nil {
+		return err
+	}
+
+	result, err := gofee.GenerateResult(length, config)
+	if err != nil {
+		return fmt.Errorf("error generating password: %v", err)
+	}
+	if err := c.Copy(result.Password); err != nil {
+		return fmt.Errorf("error copying the password: %v", err)
+	}
+	fmt.Printf("Copied the password to the clipboard (entropy %s).\n", color.GreenString("%.2f bits", result.Entropy))
+
+	if timeout <= 0 {
+		return nil
+	}
+	fmt.Printf("Clearing the clipboard in %s, press Ctrl+C to clear it now.\n", timeout)
+	waitClipboard(timeout)
+
+	cleared, err := clearClipboard(c, result.Password)
+	if err != nil {
+		return fmt.Errorf("error clearing the clipboard: %v", err)
+	}
+	if cleared {
+		fmt.Println("Cleared the clipboard.")
+	} else {
+		fmt.Println("The clipboard changed and was not cleared.")
+	}
+	return nil
+}
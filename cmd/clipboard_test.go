@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClipboard is an in-memory clipboard recording the copied texts.
+type fakeClipboard struct {
+	text   string
+	copies []string
+	// changed replaces the text when the clipboard is read, as if the user copied it.
+	changed string
+}
+
+func (c *fakeClipboard) Copy(text string) error {
+	c.text = text
+	c.copies = append(c.copies, text)
+	return nil
+}
+
+func (c *fakeClipboard) Paste() (string, error) {
+	if c.changed != "" {
+		c.text = c.changed
+	}
+	return c.text, nil
+}
+
+// useFakeClipboard replaces the clipboard and the waiting for the duration of the test.
+func useFakeClipboard(t *testing.T, c clipboard) *[]time.Duration {
+	t.Helper()
+
+	oldOpen, oldWait := openClipboard, waitClipboard
+	t.Cleanup(func() { openClipboard, waitClipboard = oldOpen, oldWait })
+
+	var waits []time.Duration
+	openClipboard = func() (clipboard, error) { return c, nil }
+	waitClipboard = func(timeout time.Duration) { waits = append(waits, timeout) }
+	return &waits
+}
+
+func TestFindClipboard(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		installed []string
+		terminal  bool
+		want      clipboard
+		wantErr   bool
+	}{
+		{
+			name:      "Wayland",
+			env:       map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			installed: []string{"wl-copy", "wl-paste", "xclip"},
+			want:      clipboardCommands[0].commandClipboard,
+		},
+		{
+			name:      "X11 without wl-clipboard",
+			env:       map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			installed: []string{"xclip"},
+			want:      clipboardCommands[1].commandClipboard,
+		},
+		{
+			name:      "xsel",
+			env:       map[string]string{"DISPLAY": ":0"},
+			installed: []string{"wl-copy", "wl-paste", "xsel"},
+			want:      clipboardCommands[2].commandClipboard,
+		},
+		{
+			name:      "No display",
+			installed: []string{"xclip", "xsel"},
+			terminal:  true,
+			want:      osc52Clipboard{w: &bytes.Buffer{}},
+		},
+		{
+			name:      "Nothing",
+			installed: []string{"xclip"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			lookPath := func(file string) (string, error) {
+				for _, name := range tt.installed {
+					if name == file {
+						return "/usr/bin/" + file, nil
+					}
+				}
+				return "", exec.ErrNotFound
+			}
+			var terminal io.Writer
+			if tt.terminal {
+				terminal = &bytes.Buffer{}
+			}
+
+			got, err := findClipboard(getenv, lookPath, terminal)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findClipboard() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findClipboard() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOSC52Clipboard(t *testing.T) {
+	var buf bytes.Buffer
+	c := osc52Clipboard{w: &buf}
+
+	if err := c.Copy("hunter2"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if want := "\x1b]52;c;aHVudGVyMg==\a"; buf.String() != want {
+		t.Errorf("Copy() wrote %q, want %q", buf.String(), want)
+	}
+	if _, err := c.Paste(); !errors.Is(err, errPasteUnsupported) {
+		t.Errorf("Paste() error = %v, want %v", err, errPasteUnsupported)
+	}
+}
+
+func TestClearClipboard(t *testing.T) {
+	tests := []struct {
+		name        string
+		clipboard   clipboard
+		wantCleared bool
+	}{
+		{"Unchanged", &fakeClipboard{text: "hunter2"}, true},
+		{"Changed", &fakeClipboard{text: "hunter2", changed: "something else"}, false},
+		{"Unreadable", osc52Clipboard{w: &bytes.Buffer{}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleared, err := clearClipboard(tt.clipboard, "hunter2")
+			if err != nil {
+				t.Fatalf("clearClipboard() error = %v", err)
+			}
+			if cleared != tt.wantCleared {
+				t.Errorf("clearClipboard() = %v, want %v", cleared, tt.wantCleared)
+			}
+			if fake, ok := tt.clipboard.(*fakeClipboard); ok && cleared && fake.text != "" {
+				t.Errorf("clearClipboard() left %q on the clipboard", fake.text)
+			}
+		})
+	}
+}
+
+func TestRootCmdWithClip(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		clipboard  *fakeClipboard
+		wantWaits  []time.Duration
+		wantCopies int
+		want       string
+	}{
+		{
+			name:       "Cleared",
+			args:       []string{"--clip", "--clip-timeout", "10s"},
+			clipboard:  &fakeClipboard{},
+			wantWaits:  []time.Duration{10 * time.Second},
+			wantCopies: 2,
+			want:       "Cleared the clipboard.",
+		},
+		{
+			name:       "Changed",
+			args:       []string{"--clip"},
+			clipboard:  &fakeClipboard{changed: "something else"},
+			wantWaits:  []time.Duration{defaultClipTimeout},
+			wantCopies: 1,
+			want:       "The clipboard changed and was not cleared.",
+		},
+		{
+			name:       "Kept",
+			args:       []string{"--clip", "--clip-timeout", "0"},
+			clipboard:  &fakeClipboard{},
+			wantCopies: 1,
+			want:       "Copied the password to the clipboard",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer resetFlags(t)
+			defer rootCmd.SetArgs(nil)
+
+			waits := useFakeClipboard(t, tt.clipboard)
+			rootCmd.SetArgs(append([]string{"--length", "20"}, tt.args...))
+
+			output, err := captureOutput(func() {
+				if err := rootCmd.Execute(); err != nil {
+					t.Fatalf("error executing rootCmd: %v", err)
+				}
+			})
+			if err != nil {
+				t.Fatalf("failed to capture output: %v", err)
+			}
+
+			if !strings.Contains(output, tt.want) {
+				t.Errorf("expected output to contain %q, but got %q", tt.want, output)
+			}
+			if len(tt.clipboard.copies) != tt.wantCopies || len(tt.clipboard.copies[0]) != 20 {
+				t.Fatalf("expected %d copies starting with a password, but got %q", tt.wantCopies, tt.clipboard.copies)
+			}
+			if strings.Contains(output, tt.clipboard.copies[0]) {
+				t.Errorf("expected the password not to be printed, but got %q", output)
+			}
+			if !reflect.DeepEqual(*waits, tt.wantWaits) {
+				t.Errorf("expected waits %v, but got %v", tt.wantWaits, *waits)
+			}
+		})
+	}
+}
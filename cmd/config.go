@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// flagSource is where the value of a flag comes from, ordered by precedence.
+type flagSource int
+
+const (
+	sourceDefault flagSource = iota
+	sourceConfig
+	sourceProfile
+	sourceEnv
+	sourceFlag
+)
+
+func (s flagSource) String() string {
+	switch s {
+	case sourceConfig:
+		return "config"
+	case sourceProfile:
+		return "profile"
+	case sourceEnv:
+		return "env"
+	case sourceFlag:
+		return "flag"
+	}
+	return "default"
+}
+
+// envPrefix is the prefix of the environment variables setting flags, as in GOFEE_LENGTH.
+const envPrefix = "GOFEE_"
+
+// unconfigurableFlags lists the flags which cannot be set by the configuration.
+var unconfigurableFlags = map[string]bool{"help": true, "version": true, "profile": true}
+
+// mutuallyExclusiveAnnotation is the annotation cobra uses for flags marked as mutually exclusive.
+const mutuallyExclusiveAnnotation = "cobra_annotation_mutually_exclusive"
+
+// userConfig is the configuration file. Defaults and profiles map flag names to their values:
+//
+//	defaults:
+//	  length: 20
+//	  no-ambiguous: true
+//	profiles:
+//	  wifi:
+//	    length: 24
+//	    exclude-symbols: true
+type userConfig struct {
+	Defaults map[string]any            `yaml:"defaults"`
+	Profiles map[string]map[string]any `yaml:"profiles"`
+}
+
+// flagSources holds the source of every flag of the root command after resolveFlags.
+var flagSources = map[string]flagSource{}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&options.profile, "profile", "p", "", "profile of the configuration file to use (also GOFEE_PROFILE)")
+	rootCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		sources, err := resolveFlags(cmd.Flags())
+		flagSources = sources
+		return err
+	}
+
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration file",
+	Long: `
+Default flags and named profiles are read from the configuration file, which is
+$XDG_CONFIG_HOME/gofee/config.yaml on Linux. It maps flag names to their values:
+
+  defaults:
+    length: 20
+    no-ambiguous: true
+  profiles:
+    wifi:
+      length: 24
+      exclude-symbols: true
+
+A profile is selected with gofee -p wifi or GOFEE_PROFILE=wifi. Every flag can also be set by
+an environment variable, such as GOFEE_LENGTH=20 or GOFEE_EXCLUDE_SYMBOLS=true.
+
+Flags on the command line take precedence over environment variables, which take precedence
+over the profile, which takes precedence over the defaults of the configuration file.
+`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration and where each value comes from",
+	Example: `
+gofee config show
+gofee config show -p wifi
+`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sources, err := resolveFlags(rootCmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		path, err := configPath()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil {
+			path += " (not found)"
+		}
+		fmt.Printf("Config file: %s\n", path)
+		if profile := selectedProfile(); profile != "" {
+			fmt.Printf("Profile:     %s\n", profile)
+		}
+		fmt.Println()
+
+		return writeFlags(os.Stdout, rootCmd.Flags(), sources)
+	},
+}
+
+// writeFlags writes the value and source of every configurable flag as a table.
+func writeFlags(w io.Writer, flags *pflag.FlagSet, sources map[string]flagSource) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tVALUE\tSOURCE")
+	flags.VisitAll(func(f *pflag.Flag) {
+		if unconfigurableFlags[f.Name] {
+			return
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Name, f.Value, sources[f.Name])
+	})
+	return tw.Flush()
+}
+
+// configPath returns the path of the configuration file, $XDG_CONFIG_HOME/gofee/config.yaml on Linux.
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gofee", "config.yaml"), nil
+}
+
+// loadUserConfig reads the configuration file. A missing file is an empty configuration.
+func loadUserConfig(path string) (*userConfig, error) {
+	config := &userConfig{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// selectedProfile returns the name of the profile given by the flag or the environment.
+func selectedProfile() string {
+	if options.profile != "" {
+		return options.profile
+	}
+	return os.Getenv(envPrefix + "PROFILE")
+}
+
+// configLayer holds the values of flags from one source.
+type configLayer struct {
+	source flagSource
+	name   string // The name of the layer in errors.
+	values map[string]any
+}
+
+// resolveFlags sets the flags which are not given on the command line from the environment,
+// the selected profile and the defaults of the configuration file, in this order. Values
+// conflicting with a mutually exclusive flag of a higher source are ignored, so that
+// --pattern on the command line overrides a default length. It returns the source of
+// every flag.
+func resolveFlags(flags *pflag.FlagSet) (map[string]flagSource, error) {
+	sources := map[string]flagSource{}
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			sources[f.Name] = sourceFlag
+		}
+	})
+
+	path, err := configPath()
+	if err != nil {
+		return sources, err
+	}
+	config, err := loadUserConfig(path)
+	if err != nil {
+		return sources, err
+	}
+
+	// Environment variables of the flags, such as GOFEE_EXCLUDE_SYMBOLS.
+	env := map[string]any{}
+	flags.VisitAll(func(f *pflag.Flag) {
+		name := envPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if value, ok := os.LookupEnv(name); ok && !unconfigurableFlags[f.Name] {
+			env[f.Name] = value
+		}
+	})
+
+	layers := []configLayer{{sourceEnv, "environment", env}}
+	if profile := selectedProfile(); profile != "" {
+		values, ok := config.Profiles[profile]
+		if !ok {
+			return sources, fmt.Errorf("profile %q not found in %s", profile, path)
+		}
+		layers = append(layers, configLayer{sourceProfile, "profile " + profile, values})
+	}
+	layers = append(layers, configLayer{sourceConfig, "defaults of " + path, config.Defaults})
+
+	for _, layer := range layers {
+		if err := applyLayer(flags, layer, sources); err != nil {
+			return sources, err
+		}
+	}
+	return sources, nil
+}
+
+// applyLayer sets the flags of a layer which are not set by a higher source.
+func applyLayer(flags *pflag.FlagSet, layer configLayer, sources map[string]flagSource) error {
+	names := make([]string, 0, len(layer.values))
+	for name := range layer.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := flags.Lookup(name)
+		if f == nil || unconfigurableFlags[name] {
+			return fmt.Errorf("unknown flag %q in %s", name, layer.name)
+		}
+		if _, ok := sources[name]; ok {
+			continue
+		}
+
+		conflict, ok := exclusiveFlag(f, sources)
+		if ok && sources[conflict] == layer.source {
+			return fmt.Errorf("flags %s and %s in %s cannot be combined", conflict, name, layer.name)
+		} else if ok {
+			continue
+		}
+
+		value, err := configValue(layer.values[name])
+		if err != nil {
+			return fmt.Errorf("invalid value of %s in %s: %v", name, layer.name, err)
+		}
+		if err := flags.Set(name, value); err != nil {
+			return fmt.Errorf("invalid value of %s in %s: %v", name, layer.name, err)
+		}
+		sources[name] = layer.source
+	}
+	return nil
+}
+
+// exclusiveFlag returns a flag which is already set and mutually exclusive with f.
+func exclusiveFlag(f *pflag.Flag, sources map[string]flagSource) (string, bool) {
+	for _, group := range f.Annotations[mutuallyExclusiveAnnotation] {
+		for _, name := range strings.Fields(group) {
+			if _, ok := sources[name]; ok && name != f.Name {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// configValue formats a YAML value as the value of a flag. Lists are joined by commas.
+func configValue(value any) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", fmt.Errorf("missing value")
+	case map[string]any:
+		return "", fmt.Errorf("unexpected mapping")
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ","), nil
+	}
+	return fmt.Sprint(value), nil
+}
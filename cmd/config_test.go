@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// writeConfig writes a configuration file into a temporary config directory and points
+// XDG_CONFIG_HOME to it.
+func writeConfig(t *testing.T, data string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "gofee"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gofee", "config.yaml"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testFlags returns a flag set with a few flags of the root command.
+func testFlags(t *testing.T) *pflag.FlagSet {
+	t.Helper()
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.IntP("length", "l", 16, "")
+	flags.String("pattern", "", "")
+	flags.Bool("exclude-symbols", false, "")
+	flags.StringSlice("user-input", nil, "")
+	for _, name := range []string{"length", "pattern"} {
+		if err := flags.SetAnnotation(name, mutuallyExclusiveAnnotation, []string{"pattern length"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return flags
+}
+
+const testConfig = `
+defaults:
+  length: 20
+  exclude-symbols: true
+  user-input: [alice, wonderland]
+profiles:
+  wifi:
+    length: 24
+  code:
+    pattern: d{6}
+  broken:
+    pattern: d{6}
+    length: 6
+  unknown:
+    lenght: 6
+`
+
+func TestResolveFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		env         map[string]string
+		profile     string
+		wantValues  map[string]string
+		wantSources map[string]flagSource
+	}{
+		{
+			name:        "Defaults",
+			wantValues:  map[string]string{"length": "20", "exclude-symbols": "true", "user-input": "[alice,wonderland]"},
+			wantSources: map[string]flagSource{"length": sourceConfig, "exclude-symbols": sourceConfig, "pattern": sourceDefault},
+		},
+		{
+			name:        "Profile",
+			profile:     "wifi",
+			wantValues:  map[string]string{"length": "24", "exclude-symbols": "true"},
+			wantSources: map[string]flagSource{"length": sourceProfile, "exclude-symbols": sourceConfig},
+		},
+		{
+			name:        "Profile from the environment",
+			env:         map[string]string{"GOFEE_PROFILE": "wifi"},
+			wantValues:  map[string]string{"length": "24"},
+			wantSources: map[string]flagSource{"length": sourceProfile},
+		},
+		{
+			name:        "Environment",
+			profile:     "wifi",
+			env:         map[string]string{"GOFEE_LENGTH": "30", "GOFEE_EXCLUDE_SYMBOLS": "false"},
+			wantValues:  map[string]string{"length": "30", "exclude-symbols": "false"},
+			wantSources: map[string]flagSource{"length": sourceEnv, "exclude-symbols": sourceEnv},
+		},
+		{
+			name:        "Flags",
+			args:        []string{"--length", "12"},
+			profile:     "wifi",
+			env:         map[string]string{"GOFEE_LENGTH": "30"},
+			wantValues:  map[string]string{"length": "12"},
+			wantSources: map[string]flagSource{"length": sourceFlag},
+		},
+		{
+			name:        "Exclusive flag",
+			args:        []string{"--pattern", "d{4}"},
+			wantValues:  map[string]string{"length": "16", "pattern": "d{4}"},
+			wantSources: map[string]flagSource{"length": sourceDefault, "pattern": sourceFlag},
+		},
+		{
+			name:        "Exclusive profile",
+			profile:     "code",
+			wantValues:  map[string]string{"length": "16", "pattern": "d{6}"},
+			wantSources: map[string]flagSource{"length": sourceDefault, "pattern": sourceProfile},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, testConfig)
+			t.Setenv("GOFEE_PROFILE", "")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			options.profile = tt.profile
+			defer func() { options.profile = "" }()
+
+			flags := testFlags(t)
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			sources, err := resolveFlags(flags)
+			if err != nil {
+				t.Fatalf("resolveFlags() error = %v", err)
+			}
+			for name, want := range tt.wantValues {
+				if got := flags.Lookup(name).Value.String(); got != want {
+					t.Errorf("resolveFlags() %s = %q, want %q", name, got, want)
+				}
+			}
+			for name, want := range tt.wantSources {
+				if got := sources[name]; got != want {
+					t.Errorf("resolveFlags() source of %s = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveFlagsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		profile string
+		env     map[string]string
+		wantErr string
+	}{
+		{"Missing profile", testConfig, "missing", nil, `profile "missing" not found`},
+		{"Unknown flag", testConfig, "unknown", nil, `unknown flag "lenght" in profile unknown`},
+		{"Exclusive flags", testConfig, "broken", nil, "flags length and pattern in profile broken cannot be combined"},
+		{"Invalid value", "defaults:\n  length: twenty\n", "", nil, "invalid value of length"},
+		{"Invalid environment", "", "", map[string]string{"GOFEE_LENGTH": "twenty"}, "invalid value of length in environment"},
+		{"Unknown field", "default:\n  length: 20\n", "", nil, "invalid config file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, tt.config)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			options.profile = tt.profile
+			defer func() { options.profile = "" }()
+
+			_, err := resolveFlags(testFlags(t))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resolveFlags() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRootCmdWithProfile(t *testing.T) {
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	writeConfig(t, "defaults:\n  output: plain\nprofiles:\n  pin:\n    type: pin\n    length: 6\n")
+	t.Setenv("GOFEE_COUNT", "3")
+	rootCmd.SetArgs([]string{"-p", "pin"})
+
+	output, err := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("error executing rootCmd: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	if !regexp.MustCompile(`^([0-9]{6}\n){3}$`).MatchString(output) {
+		t.Errorf("expected 3 PINs of 6 digits, but got %q", output)
+	}
+}
+
+func TestRootCmdWithConfigMemorable(t *testing.T) {
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	// A default length of characters does not become the number of words.
+	writeConfig(t, "defaults:\n  length: 30\n  output: plain\n")
+	rootCmd.SetArgs([]string{"--type", "memorable", "--separator", "-"})
+
+	output, err := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("error executing rootCmd: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	if words := strings.Split(strings.TrimSpace(output), "-"); len(words) != defaultWords {
+		t.Errorf("expected %d words, but got %q", defaultWords, output)
+	}
+}
+
+func TestConfigShowCmd(t *testing.T) {
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	writeConfig(t, "defaults:\n  length: 20\n  exclude-symbols: true\nprofiles:\n  wifi:\n    length: 24\n")
+	t.Setenv("GOFEE_COUNT", "5")
+	rootCmd.SetArgs([]string{"config", "show", "-p", "wifi"})
+
+	output, err := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("error executing config show: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	for _, want := range []string{`Config file: .*gofee/config\.yaml\n`, `Profile: +wifi\n`, `\nlength +24 +profile\n`, `\nexclude-symbols +true +config\n`, `\ncount +5 +env\n`, `\ntype +default\n`} {
+		if !regexp.MustCompile(want).MatchString(output) {
+			t.Errorf("expected output to match %q, but got %q", want, output)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// Options for the derive command
+var deriveOptions struct {
+	site         string
+	user         string
+	counter      uint32
+	version      int
+	length       int
+	passwordType string
+	lowers       bool
+	uppers       bool
+	digits       bool
+	symbols      bool
+	requireAll   bool
+	charset      string
+	symbolSet    string
+	excludeChars string
+	noAmbiguous  bool
+	output       string
+	noColor      bool
+}
+
+func init() {
+	deriveCmd.Flags().StringVar(&deriveOptions.site, "site", "", "site the password is used for, such as example.com")
+	deriveCmd.Flags().StringVar(&deriveOptions.user, "user", "", "user name on the site")
+	deriveCmd.Flags().Uint32Var(&deriveOptions.counter, "counter", 1, "counter, incremented to change the password of a site")
+	deriveCmd.Flags().IntVar(&deriveOptions.version, "algorithm", gofee.DeriveVersion, "version of the derivation algorithm")
+	deriveCmd.Flags().IntVarP(&deriveOptions.length, "length", "l", defaultLength, "length of the password")
+	deriveCmd.Flags().StringVarP(&deriveOptions.passwordType, "type", "t", "", "type of password to derive (pin)")
+	deriveCmd.Flags().BoolVarP(&deriveOptions.lowers, "exclude-lowers", "w", false, "exclude lowercase letters")
+	deriveCmd.Flags().BoolVarP(&deriveOptions.uppers, "exclude-uppers", "u", false, "exclude uppercase letters")
+	deriveCmd.Flags().BoolVarP(&deriveOptions.digits, "exclude-digits", "d", false, "exclude digits")
+	deriveCmd.Flags().BoolVarP(&deriveOptions.symbols, "exclude-symbols", "s", false, "exclude symbols")
+	deriveCmd.Flags().BoolVarP(&deriveOptions.requireAll, "require-all", "r", false, "require at least one character of every included class")
+	deriveCmd.Flags().StringVar(&deriveOptions.charset, "charset", "", "explicit alphabet to derive the password from")
+	deriveCmd.Flags().StringVar(&deriveOptions.symbolSet, "symbols", "", "symbols to use instead of the default symbols")
+	deriveCmd.Flags().StringVarP(&deriveOptions.excludeChars, "exclude-chars", "x", "", "characters to exclude from the password")
+	deriveCmd.Flags().BoolVar(&deriveOptions.noAmbiguous, "no-ambiguous", false, "exclude visually ambiguous characters ("+gofee.Ambiguous+")")
+	deriveCmd.Flags().StringVarP(&deriveOptions.output, "output", "o", formatText, "output format ("+formatText+", "+formatPlain+", "+formatJSON+")")
+	deriveCmd.Flags().BoolVar(&deriveOptions.noColor, "no-color", false, "disable colored output")
+	_ = deriveCmd.MarkFlagRequired("site")
+
+	rootCmd.AddCommand(deriveCmd)
+}
+
+var deriveCmd = &cobra.Command{
+	Use:   "derive",
+	Short: "Derive the password of a site from a master password",
+	Long: `
+Derive computes the password of a site from a master password, the site, the user name and a
+counter, so the same password is derived again on any machine without storing it. Increment
+the counter to change the password of a site.
+
+The master password is stretched with Argon2id and the derived bytes are mapped onto the
+charset without bias. The algorithm is versioned and never changes within a version, so
+passwords stay the same across releases of gofee. The master password is read from stdin, on
+a terminal it is prompted for without echo.
+
+Derived passwords are only as strong as the master password: anyone who learns it can derive
+the passwords of all sites.
+`,
+	Example: `
+gofee derive --site example.com --user alice
+gofee derive --site example.com --user alice --counter 2 --length 24 --exclude-symbols
+gofee derive --site bank.example --type pin --length 6
+`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch deriveOptions.output {
+		case formatText, formatPlain, formatJSON:
+		default:
+			return fmt.Errorf("unknown output format %q (supported: %s, %s, %s)", deriveOptions.output, formatText, formatPlain, formatJSON)
+		}
+
+		master, err := readPassword(cmd.InOrStdin(), os.Stderr, "Master password")
+		if err != nil {
+			return fmt.Errorf("error reading master password: %v", err)
+		}
+
+		config := gofee.PasswordConfig{
+			IncludeLowers:    !deriveOptions.lowers,
+			IncludeUppers:    !deriveOptions.uppers,
+			IncludeDigits:    !deriveOptions.digits,
+			IncludeSymbols:   !deriveOptions.symbols,
+			RequireAll:       deriveOptions.requireAll,
+			Type:             deriveOptions.passwordType,
+			CustomCharset:    deriveOptions.charset,
+			CustomSymbols:    deriveOptions.symbolSet,
+			ExcludeChars:     deriveOptions.excludeChars,
+			ExcludeAmbiguous: deriveOptions.noAmbiguous,
+		}
+		result, err := gofee.Derive(master, deriveOptions.length, config, gofee.DeriveParams{
+			Site:    deriveOptions.site,
+			User:    deriveOptions.user,
+			Counter: deriveOptions.counter,
+			Version: deriveOptions.version,
+		})
+		if err != nil {
+			return fmt.Errorf("error deriving password: %v", err)
+		}
+
+		if deriveOptions.noColor || !isTerminal(os.Stdout) {
+			color.NoColor = true
+		}
+
+		rw, err := newResultWriter(os.Stdout, deriveOptions.output, "", 1)
+		if err != nil {
+			return err
+		}
+		rw.setSymbols(config.CustomSymbols)
+		return rw.Write(result)
+	},
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// executeDerive runs the derive command with the master password on stdin and returns its output.
+func executeDerive(t *testing.T, master string, args ...string) (string, error) {
+	t.Helper()
+	defer resetFlags(t)
+	defer rootCmd.SetIn(nil)
+	defer rootCmd.SetArgs(nil)
+
+	rootCmd.SetIn(strings.NewReader(master))
+	rootCmd.SetArgs(append([]string{"derive"}, args...))
+
+	var execErr error
+	output, err := captureOutput(func() {
+		execErr = rootCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+	return output, execErr
+}
+
+func TestDeriveCmd(t *testing.T) {
+	output, err := executeDerive(t, "correct horse battery staple\n", "--site", "example.com", "--user", "alice", "--output", "plain")
+	if err != nil {
+		t.Fatalf("error executing derive: %v", err)
+	}
+
+	// The first test vector of the derivation algorithm, with the default counter of 1.
+	if want := "Y+5jB7$#aI6jnki)\n"; output != want {
+		t.Errorf("expected %q, but got %q", want, output)
+	}
+}
+
+func TestDeriveCmdOptions(t *testing.T) {
+	output, err := executeDerive(t, "Tr0ub4dor&3", "--site", "bank.example", "--user", "alice", "--type", "pin", "--length", "6", "--output", "json")
+	if err != nil {
+		t.Fatalf("error executing derive: %v", err)
+	}
+
+	var got jsonResult
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("failed to parse %q: %v", output, err)
+	}
+	if got.Password != "778321" || got.Length != 6 {
+		t.Errorf("expected the PIN 778321, but got %+v", got)
+	}
+}
+
+func TestDeriveCmdCounter(t *testing.T) {
+	first, err := executeDerive(t, "secret", "--site", "example.com", "--output", "plain")
+	if err != nil {
+		t.Fatalf("error executing derive: %v", err)
+	}
+	second, err := executeDerive(t, "secret", "--site", "example.com", "--counter", "2", "--output", "plain")
+	if err != nil {
+		t.Fatalf("error executing derive: %v", err)
+	}
+
+	if first == second {
+		t.Errorf("expected the counter to change the password, but got %q twice", first)
+	}
+}
+
+func TestDeriveCmdErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		master  string
+		args    []string
+		wantErr string
+	}{
+		{"Missing site", "secret", nil, "site"},
+		{"No master password", "", []string{"--site", "example.com"}, "no password"},
+		{"Unsupported type", "secret", []string{"--site", "example.com", "--type", "memorable"}, "charset"},
+		{"Unknown version", "secret", []string{"--site", "example.com", "--algorithm", "9"}, "version 9"},
+		{"Unknown output", "secret", []string{"--site", "example.com", "--output", "csv"}, "output format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executeDerive(t, tt.master, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	hashCmd.AddCommand(hashVerifyCmd)
+	rootCmd.AddCommand(hashCmd)
+}
+
+var hashCmd = &cobra.Command{
+	Use:   "hash",
+	Short: "Verify password hashes",
+	Long: `
+Passwords are hashed with gofee --hash, which prints the hash next to or instead of the password.
+The hashes are in the formats used by crypt(3) and the PHC string format, so they can be stored
+as they are:
+  bcrypt        $2a$12$...
+  argon2id      $argon2id$v=19$m=65536,t=3,p=4$...
+  scrypt        $scrypt$ln=17,r=8,p=1$...
+  sha512-crypt  $6$rounds=656000$...
+  pbkdf2        $pbkdf2-sha256$i=600000$...
+`,
+	Args: cobra.NoArgs,
+}
+
+var hashVerifyCmd = &cobra.Command{
+	Use:   "verify HASH",
+	Short: "Verify a password read from stdin against a hash",
+	Long: `
+Verify checks whether a password matches a hash created by gofee --hash or by another
+implementation of the supported algorithms (` + strings.Join(gofee.HashAlgorithms, ", ") + `).
+The command fails if the password does not match.
+
+The password is read from stdin and never accepted as an argument, so it does not end up in the
+shell history or the process list. On a terminal, the password is prompted for without echo.
+Quote the hash, as it contains $ characters.
+`,
+	Example: `
+gofee hash verify '$argon2id$v=19$m=65536,t=3,p=4$...'
+echo 'correct horse battery staple' | gofee hash verify "$(cat password.hash)"
+`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		password, err := readPassword(cmd.InOrStdin(), os.Stderr, "Password")
+		if err != nil {
+			return fmt.Errorf("error reading password: %v", err)
+		}
+
+		ok, err := gofee.VerifyPassword(password, args[0])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("the password does not match the hash")
+		}
+		fmt.Println("The password matches the hash.")
+		return nil
+	},
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+)
+
+// executeHashVerify runs hash verify with the password on stdin and returns its output.
+func executeHashVerify(t *testing.T, password, hashed string) (string, error) {
+	t.Helper()
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+	defer rootCmd.SetIn(nil)
+
+	rootCmd.SetArgs([]string{"hash", "verify", hashed})
+	rootCmd.SetIn(strings.NewReader(password + "\n"))
+
+	var execErr error
+	output, err := captureOutput(func() {
+		execErr = rootCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+	return output, execErr
+}
+
+func TestHashVerifyCmd(t *testing.T) {
+	const hashed = "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"
+
+	output, err := executeHashVerify(t, "Hello world!", hashed)
+	if err != nil {
+		t.Fatalf("error executing hash verify: %v", err)
+	}
+	if want := "The password matches the hash.\n"; output != want {
+		t.Errorf("expected %q, but got %q", want, output)
+	}
+
+	// A wrong password and an unsupported hash fail.
+	if _, err := executeHashVerify(t, "Hello world", hashed); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected a mismatch, but got %v", err)
+	}
+	if _, err := executeHashVerify(t, "Hello world!", "plaintext"); err == nil || !strings.Contains(err.Error(), "unsupported hash") {
+		t.Errorf("expected an unsupported hash, but got %v", err)
+	}
+}
+
+func TestRootCmdWithHash(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"bcrypt", []string{"--hash", "bcrypt", "--hash-cost", "4"}, `^Entropy: [0-9.]+ bits\nPassword: (\S{16})\nHash: (\$2a\$04\$\S{53})\n$`},
+		{"argon2id", []string{"--hash", "argon2id", "--hash-rounds", "1", "--hash-memory", "1024", "--hash-parallelism", "1", "-o", "plain"}, `^(\S{16})\t(\$argon2id\$v=19\$m=1024,t=1,p=1\$\S+)\n$`},
+		{"Hash only", []string{"--hash", "sha512-crypt", "--hash-rounds", "1000", "--hash-only", "-o", "plain"}, `^()(\$6\$rounds=1000\$\S+)\n$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer resetFlags(t)
+			defer rootCmd.SetArgs(nil)
+			rootCmd.SetArgs(append([]string{"--no-color"}, tt.args...))
+
+			output, err := captureOutput(func() {
+				if err := rootCmd.Execute(); err != nil {
+					t.Fatalf("error executing rootCmd: %v", err)
+				}
+			})
+			if err != nil {
+				t.Fatalf("failed to capture output: %v", err)
+			}
+
+			match := regexp.MustCompile(tt.want).FindStringSubmatch(output)
+			if match == nil {
+				t.Fatalf("expected output matching %s, but got %q", tt.want, output)
+			}
+
+			// The hash belongs to the printed password.
+			if match[1] != "" {
+				if ok, err := gofee.VerifyPassword(match[1], match[2]); err != nil || !ok {
+					t.Errorf("VerifyPassword(%q, %q) = %v, %v, want true", match[1], match[2], ok, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRootCmdHashFlagErrors(t *testing.T) {
+	// Hashes and copied or encoded passwords cannot be combined.
+	for _, args := range [][]string{
+		{"--clip", "--hash", "bcrypt"},
+		{"--clip", "--hash-only"},
+		{"--qr", "--hash-only"},
+		{"--qr-png", "password.png", "--hash-only"},
+	} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			defer resetFlags(t)
+			defer rootCmd.SetArgs(nil)
+			rootCmd.SetArgs(args)
+
+			var execErr error
+			if _, err := captureOutput(func() { execErr = rootCmd.Execute() }); err != nil {
+				t.Fatalf("failed to capture output: %v", err)
+			}
+			if execErr == nil || !strings.Contains(execErr.Error(), "none of the others can be") {
+				t.Errorf("expected mutually exclusive flags, but got %v", execErr)
+			}
+		})
+	}
+}
+
+func TestCheckHashFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		hash    gofee.HashConfig
+		only    bool
+		wantErr string
+	}{
+		{"No hash", gofee.HashConfig{}, false, ""},
+		{"Hash only", gofee.HashConfig{Algorithm: gofee.HashBcrypt}, true, ""},
+		{"Hash only without hash", gofee.HashConfig{}, true, "--hash-only requires --hash"},
+		{"Cost without hash", gofee.HashConfig{Cost: 4}, false, "--hash-cost requires --hash"},
+		{"Rounds without hash", gofee.HashConfig{Rounds: 1000}, false, "--hash-rounds requires --hash"},
+		{"Memory without hash", gofee.HashConfig{Memory: 1024}, false, "--hash-memory requires --hash"},
+		{"Parallelism without hash", gofee.HashConfig{Parallelism: 2}, false, "--hash-parallelism requires --hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer resetFlags(t)
+			options.hash, options.hashOnly = tt.hash, tt.only
+
+			err := checkHashFlags()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("checkHashFlags() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("checkHashFlags() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
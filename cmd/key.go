@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/spf13/cobra"
+)
+
+// Options for the key command
+var keyOptions struct {
+	keyType string
+	format  string
+	bits    int
+	comment string
+	out     string
+}
+
+func init() {
+	keyCmd.Flags().StringVarP(&keyOptions.keyType, "type", "t", gofee.KeyEd25519, "type of key ("+strings.Join(gofee.KeyTypes, ", ")+")")
+	keyCmd.Flags().StringVarP(&keyOptions.format, "format", "f", "", "format of the key ("+strings.Join(gofee.KeyFormats, ", ")+"), pem for key pairs and hex for symmetric keys by default")
+	keyCmd.Flags().IntVar(&keyOptions.bits, "bits", gofee.RSABits, fmt.Sprintf("size of RSA keys in bits (%d to %d)", gofee.RSAMinBits, gofee.RSAMaxBits))
+	keyCmd.Flags().StringVarP(&keyOptions.comment, "comment", "C", "", "comment of OpenSSH keys, such as alice@example.com")
+	keyCmd.Flags().StringVar(&keyOptions.out, "out", "", "write the key to this file and the public key to the file with .pub appended")
+
+	rootCmd.AddCommand(keyCmd)
+}
+
+var keyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Generate symmetric keys and key pairs",
+	Long: `
+Generates symmetric keys for AES, HMAC and ChaCha20, and key pairs for Ed25519, X25519,
+ECDSA P-256 and RSA.
+
+Formats:
+  pem      PKCS #8 private keys and PKIX public keys, for key pairs
+  jwk      JSON Web Keys, for all keys
+  openssh  OpenSSH private keys and authorized_keys lines, for Ed25519, P-256 and RSA
+  hex      hexadecimal, for symmetric keys
+  base64   Base64, for symmetric keys
+
+HMAC keys have the block size of their hash, 64 bytes for SHA-256 and 128 bytes for SHA-512.
+Without --out, the private key is printed followed by the public key. Existing files are never
+overwritten.
+`,
+	Example: `
+gofee key --type aes256
+gofee key --type hmac-sha256 --format base64
+gofee key --type ed25519 --format openssh --comment alice@example.com --out id_ed25519
+gofee key --type rsa --bits 4096 --format jwk
+`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := gofee.GenerateKey(gofee.KeyConfig{Type: keyOptions.keyType, Bits: keyOptions.bits})
+		if err != nil {
+			return err
+		}
+
+		format := keyOptions.format
+		if format == "" && key.Secret != nil {
+			format = gofee.KeyFormatHex
+		} else if format == "" {
+			format = gofee.KeyFormatPEM
+		}
+		private, public, err := key.Encode(format, keyOptions.comment)
+		if err != nil {
+			return err
+		}
+
+		if keyOptions.out == "" {
+			fmt.Print(private)
+			fmt.Print(public)
+			return nil
+		}
+
+		// Check the public key first, so no private key is left without it.
+		if _, err := os.Stat(keyOptions.out + ".pub"); public != "" && err == nil {
+			return fmt.Errorf("%s.pub already exists", keyOptions.out)
+		}
+		if err := writeNewFile(keyOptions.out, private, 0o600); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote the %d bit %s key to %s\n", key.Bits(), key.Type, keyOptions.out)
+		if public != "" {
+			if err := writeNewFile(keyOptions.out+".pub", public, 0o644); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote the public key to %s.pub\n", keyOptions.out)
+		}
+		return nil
+	},
+}
+
+// writeNewFile writes the content to a file which must not exist yet.
+func writeNewFile(path, content string, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// Options of the otp commands, shared by new and code.
+var otpOptions struct {
+	issuer    string
+	account   string
+	otpType   string
+	algorithm string
+	digits    int
+	period    time.Duration
+	counter   uint64
+	size      int
+	qr        bool
+	qrPNG     string
+	at        string
+	output    string
+	noColor   bool
+}
+
+func init() {
+	// addKeyFlags adds the flags of the settings of a key.
+	addKeyFlags := func(cmd *cobra.Command) {
+		cmd.Flags().StringVarP(&otpOptions.otpType, "type", "t", gofee.OTPTypeTOTP, "type of one-time password ("+gofee.OTPTypeTOTP+", "+gofee.OTPTypeHOTP+")")
+		cmd.Flags().StringVar(&otpOptions.algorithm, "algorithm", gofee.OTPAlgorithm, "HMAC algorithm (SHA1, SHA256, SHA512)")
+		cmd.Flags().IntVar(&otpOptions.digits, "digits", gofee.OTPDigits, fmt.Sprintf("number of digits of the codes (%d to %d)", gofee.OTPMinDigits, gofee.OTPMaxDigits))
+		cmd.Flags().DurationVar(&otpOptions.period, "period", gofee.OTPPeriod, "time a TOTP code is valid for")
+		cmd.Flags().Uint64Var(&otpOptions.counter, "counter", 0, "counter of the HOTP code")
+	}
+
+	addKeyFlags(otpNewCmd)
+	otpNewCmd.Flags().StringVar(&otpOptions.issuer, "issuer", "", "provider of the account, such as Example")
+	otpNewCmd.Flags().StringVar(&otpOptions.account, "account", "", "name of the account, such as alice@example.com")
+	otpNewCmd.Flags().IntVar(&otpOptions.size, "size", gofee.OTPSecretSize, "size of the secret in bytes")
+	otpNewCmd.Flags().BoolVar(&otpOptions.qr, "qr", false, "print the URI as a QR code")
+	otpNewCmd.Flags().StringVar(&otpOptions.qrPNG, "qr-png", "", "write the URI as a QR code to a PNG file")
+	_ = otpNewCmd.MarkFlagRequired("account")
+	otpNewCmd.MarkFlagsMutuallyExclusive("qr", "qr-png")
+
+	addKeyFlags(otpCodeCmd)
+	otpCodeCmd.Flags().StringVar(&otpOptions.at, "at", "", "compute the code at this time (RFC 3339) instead of now")
+	otpCodeCmd.Flags().StringVarP(&otpOptions.output, "output", "o", formatText, "output format ("+formatText+", "+formatPlain+")")
+	otpCodeCmd.Flags().BoolVar(&otpOptions.noColor, "no-color", false, "disable colored output")
+
+	otpCmd.AddCommand(otpNewCmd)
+	otpCmd.AddCommand(otpCodeCmd)
+	rootCmd.AddCommand(otpCmd)
+}
+
+var otpCmd = &cobra.Command{
+	Use:   "otp",
+	Short: "Generate two-factor secrets and compute their one-time passwords",
+	Long: `
+Generates secrets for two-factor authentication with time-based (TOTP, RFC 6238) or
+counter-based (HOTP, RFC 4226) one-time passwords, and computes their codes.
+
+Secrets are exchanged with authenticator apps as otpauth:// URIs, usually scanned from a QR code.
+The defaults of SHA1, 6 digits and 30 seconds are supported by all authenticator apps.
+`,
+}
+
+var otpNewCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Generate a random secret and its otpauth:// URI",
+	Example: `
+gofee otp new --issuer Example --account alice@example.com
+gofee otp new --issuer Example --account alice@example.com --qr
+gofee otp new --account alice --type hotp --digits 8 --qr-png alice.png
+`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		secret, err := gofee.NewOTPSecret(otpOptions.size)
+		if err != nil {
+			return err
+		}
+
+		key := otpKey(secret)
+		key.Issuer = otpOptions.issuer
+		key.Account = otpOptions.account
+		uri, err := key.URI()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Secret: %s\n", secret)
+		fmt.Printf("URI: %s\n", uri)
+		if otpOptions.qr || otpOptions.qrPNG != "" {
+			return writeQROutput(otpOptions.qrPNG, uri)
+		}
+		return nil
+	},
+}
+
+var otpCodeCmd = &cobra.Command{
+	Use:   "code",
+	Short: "Compute the one-time password of a secret read from stdin",
+	Long: `
+Computes the current one-time password of a Base32 secret or an otpauth:// URI, which is read
+from stdin so it does not end up in the shell history. On a terminal, it is prompted for
+without echo. The settings of a URI take precedence over the defaults of the flags, but not
+over flags given on the command line.
+`,
+	Example: `
+gofee otp code
+echo JBSWY3DPEHPK3PXP | gofee otp code --digits 8
+gofee otp code --type hotp --counter 5 < secret.txt
+gofee otp code --output plain < uri.txt
+`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if otpOptions.output != formatText && otpOptions.output != formatPlain {
+			return fmt.Errorf("unknown output format %q (supported: %s, %s)", otpOptions.output, formatText, formatPlain)
+		}
+
+		now := time.Now()
+		if otpOptions.at != "" {
+			var err error
+			if now, err = time.Parse(time.RFC3339, otpOptions.at); err != nil {
+				return fmt.Errorf("invalid time %q: %v", otpOptions.at, err)
+			}
+		}
+
+		input, err := readPassword(cmd.InOrStdin(), os.Stderr, "Secret or URI")
+		if err != nil {
+			return fmt.Errorf("error reading secret: %v", err)
+		}
+
+		key, err := otpCodeKey(cmd, strings.TrimSpace(input))
+		if err != nil {
+			return err
+		}
+		code, err := key.Code(now)
+		if err != nil {
+			return fmt.Errorf("error computing code: %v", err)
+		}
+
+		if otpOptions.output == formatPlain {
+			fmt.Println(code)
+			return nil
+		}
+
+		if otpOptions.noColor || !isTerminal(os.Stdout) {
+			color.NoColor = true
+		}
+		fmt.Printf("Code: %s\n", color.GreenString(code))
+		if key.Type == gofee.OTPTypeTOTP {
+			fmt.Printf("Valid for: %s\n", key.Remaining(now).Round(time.Second))
+		}
+		return nil
+	},
+}
+
+// otpKey returns a key with the secret and the settings of the flags.
+func otpKey(secret string) gofee.OTPKey {
+	return gofee.OTPKey{
+		Type:      strings.ToLower(otpOptions.otpType),
+		Secret:    secret,
+		Algorithm: strings.ToUpper(otpOptions.algorithm),
+		Digits:    otpOptions.digits,
+		Period:    otpOptions.period,
+		Counter:   otpOptions.counter,
+	}
+}
+
+// otpCodeKey returns the key of a Base32 secret or an otpauth:// URI. The flags given on the
+// command line override the settings of the URI.
+func otpCodeKey(cmd *cobra.Command, input string) (gofee.OTPKey, error) {
+	if !strings.HasPrefix(strings.ToLower(input), "otpauth:") {
+		return otpKey(input), nil
+	}
+
+	key, err := gofee.ParseOTPURI(input)
+	if err != nil {
+		return gofee.OTPKey{}, err
+	}
+	flags := otpKey(key.Secret)
+	if cmd.Flags().Changed("type") {
+		key.Type = flags.Type
+	}
+	if cmd.Flags().Changed("algorithm") {
+		key.Algorithm = flags.Algorithm
+	}
+	if cmd.Flags().Changed("digits") {
+		key.Digits = flags.Digits
+	}
+	if cmd.Flags().Changed("period") {
+		key.Period = flags.Period
+	}
+	if cmd.Flags().Changed("counter") {
+		key.Counter = flags.Counter
+	}
+	return key, nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+)
+
+// executeOTP runs an otp command with the given stdin and arguments and returns its output.
+func executeOTP(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+	defer resetFlags(t)
+	defer rootCmd.SetIn(nil)
+	defer rootCmd.SetArgs(nil)
+
+	rootCmd.SetIn(strings.NewReader(stdin))
+	rootCmd.SetArgs(append([]string{"otp"}, args...))
+
+	var execErr error
+	output, err := captureOutput(func() {
+		execErr = rootCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+	return output, execErr
+}
+
+func TestOTPNewCmd(t *testing.T) {
+	output, err := executeOTP(t, "", "new", "--issuer", "Example", "--account", "alice@example.com")
+	if err != nil {
+		t.Fatalf("error executing otp new: %v", err)
+	}
+
+	match := regexp.MustCompile(`^Secret: ([A-Z2-7]{32})\nURI: (otpauth://totp/Example:alice@example.com\?secret=([A-Z2-7]{32})&issuer=Example&algorithm=SHA1&digits=6&period=30)\n$`).FindStringSubmatch(output)
+	if match == nil {
+		t.Fatalf("expected the secret and the URI, but got %q", output)
+	}
+	if match[1] != match[3] {
+		t.Errorf("expected the URI to contain the secret %s, but got %s", match[1], match[2])
+	}
+}
+
+func TestOTPNewCmdQR(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "otp.png")
+	output, err := executeOTP(t, "", "new", "--account", "alice", "--type", "hotp", "--digits", "8", "--size", "32", "--qr-png", path)
+	if err != nil {
+		t.Fatalf("error executing otp new: %v", err)
+	}
+
+	if !regexp.MustCompile(`URI: otpauth://hotp/alice\?secret=[A-Z2-7]{52}&algorithm=SHA1&digits=8&counter=0\n`).MatchString(output) {
+		t.Errorf("expected an HOTP URI, but got %q", output)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected the PNG file to be written: %v", err)
+	}
+
+	output, err = executeOTP(t, "", "new", "--account", "alice", "--qr")
+	if err != nil {
+		t.Fatalf("error executing otp new: %v", err)
+	}
+	if !strings.Contains(output, "█") {
+		t.Errorf("expected a QR code, but got %q", output)
+	}
+}
+
+func TestOTPCodeCmd(t *testing.T) {
+	// The RFC 6238 secret 12345678901234567890 in Base32.
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+		want  string
+	}{
+		{"TOTP", secret, []string{"--digits", "8", "--at", "2005-03-18T01:58:29Z"}, "Code: 07081804\nValid for: 1s\n"},
+		{"HOTP", secret, []string{"--type", "hotp", "--counter", "1"}, "Code: 287082\n"},
+		{"Plain", secret, []string{"--digits", "8", "--at", "2009-02-13T23:31:30Z", "--output", "plain"}, "89005924\n"},
+		{"Spaced lowercase secret", "gezd gnbv gy3t qojq gezd gnbv gy3t qojq\n", []string{"--digits", "8", "--at", "1970-01-01T00:00:59Z", "--output", "plain"}, "94287082\n"},
+		{"URI", "otpauth://totp/alice?secret=" + secret + "&digits=8", []string{"--at", "2033-05-18T03:33:20Z", "--output", "plain"}, "69279037\n"},
+		{"URI with flag", "otpauth://totp/alice?secret=" + secret + "&digits=8", []string{"--digits", "6", "--at", "2033-05-18T03:33:20Z", "--output", "plain"}, "279037\n"},
+		{"HOTP URI", "otpauth://hotp/alice?secret=" + secret + "&counter=9", []string{"--output", "plain"}, "520489\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := executeOTP(t, tt.stdin, append([]string{"code", "--no-color"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("error executing otp code: %v", err)
+			}
+			if output != tt.want {
+				t.Errorf("expected %q, but got %q", tt.want, output)
+			}
+		})
+	}
+}
+
+func TestOTPCodeCmdErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		stdin   string
+		args    []string
+		wantErr string
+	}{
+		{"Invalid secret", "not base32!", nil, "Base32"},
+		{"No secret", "", nil, "no password"},
+		{"Invalid URI", "otpauth://totp/alice", nil, "secret"},
+		{"Invalid time", "JBSWY3DPEHPK3PXP", []string{"--at", "yesterday"}, "invalid time"},
+		{"Unknown algorithm", "JBSWY3DPEHPK3PXP", []string{"--algorithm", "MD5"}, "algorithm"},
+		{"Unknown output", "JBSWY3DPEHPK3PXP", []string{"--output", "json"}, "output format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executeOTP(t, tt.stdin, append([]string{"code"}, tt.args...)...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOTPNewCmdErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"Missing account", nil, "account"},
+		{"Small secret", []string{"--account", "alice", "--size", "10"}, "at least"},
+		{"Colon in account", []string{"--account", "alice:bob"}, "colon"},
+		{"Digits", []string{"--account", "alice", "--digits", "4"}, "digits"},
+		{"Both QR outputs", []string{"--account", "alice", "--qr", "--qr-png", "otp.png"}, "qr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executeOTP(t, "", append([]string{"new"}, tt.args...)...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestOTPKeyFromFlags checks that the flags are turned into the settings of a key.
+func TestOTPKeyFromFlags(t *testing.T) {
+	defer resetFlags(t)
+
+	otpOptions.otpType = "HOTP"
+	otpOptions.algorithm = "sha256"
+	key := otpKey("JBSWY3DPEHPK3PXP")
+	if key.Type != gofee.OTPTypeHOTP || key.Algorithm != "SHA256" || key.Digits != gofee.OTPDigits {
+		t.Errorf("unexpected key %+v", key)
+	}
+}
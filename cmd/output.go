@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/fatih/color"
+)
+
+// Output formats supported by the --output flag.
+const (
+	formatText  = "text"
+	formatPlain = "plain"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+	formatEnv   = "env"
+)
+
+// outputFormats lists the supported output formats in the order they are documented.
+var outputFormats = []string{formatText, formatPlain, formatJSON, formatCSV, formatEnv}
+
+// jsonResult is the JSON representation of a generated password.
+type jsonResult struct {
+	Password string   `json:"password,omitempty"`
+	Length   int      `json:"length"`
+	Classes  []string `json:"classes"`
+	Entropy  float64  `json:"entropy"`
+	Strength string   `json:"strength"`
+	Hash     string   `json:"hash,omitempty"`
+}
+
+// resultWriter writes generated passwords in one of the output formats.
+type resultWriter struct {
+	w       io.Writer
+	format  string
+	envName string
+	count   int
+	written int
+	csv     *csv.Writer
+
+	// The symbols of the passwords, gofee.Symbols if empty.
+	symbols string
+
+	// The passwords are hashed if the algorithm of hash is set.
+	hash     gofee.HashConfig
+	hashOnly bool
+}
+
+// envNamePattern matches the names of shell variables.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// newResultWriter returns a resultWriter for count passwords in the given format.
+// envName is the variable name used by the env format. It returns an error if the format is
+// unknown or envName is not a valid variable name.
+func newResultWriter(w io.Writer, format, envName string, count int) (*resultWriter, error) {
+	rw := &resultWriter{w: w, format: format, envName: envName, count: count}
+
+	switch format {
+	case formatText, formatPlain, formatJSON:
+	case formatEnv:
+		if !envNamePattern.MatchString(envName) {
+			return nil, fmt.Errorf("invalid variable name %q, which must consist of letters, digits and underscores and not start with a digit", envName)
+		}
+	case formatCSV:
+		rw.csv = csv.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown output format %q (supported: %s)", format, strings.Join(outputFormats, ", "))
+	}
+
+	return rw, nil
+}
+
+// setHash makes the writer add the hash of every password, or write only the hash if only is set.
+func (rw *resultWriter) setHash(config gofee.HashConfig, only bool) {
+	rw.hash = config
+	rw.hashOnly = only
+}
+
+// setSymbols sets the symbols the passwords were generated with, if they are not gofee.Symbols.
+func (rw *resultWriter) setSymbols(symbols string) {
+	rw.symbols = symbols
+}
+
+// Write writes a single password. Passwords are written as they arrive, so the output can be streamed.
+func (rw *resultWriter) Write(result gofee.Result) error {
+	// Hash the password first, so nothing is written if hashing fails.
+	var hashed string
+	if rw.hash.Algorithm != "" {
+		var err error
+		if hashed, err = gofee.HashPassword(result.Password, rw.hash); err != nil {
+			return err
+		}
+	}
+
+	// Write the parts that precede the first password.
+	if rw.written == 0 {
+		if err := rw.writeHeader(result); err != nil {
+			return err
+		}
+	}
+	rw.written++
+
+	var err error
+	switch rw.format {
+	case formatText:
+		if !rw.hashOnly {
+			_, err = fmt.Fprintf(rw.w, "Password: %s\n", color.GreenString(result.Password))
+		}
+		if err == nil && hashed != "" {
+			_, err = fmt.Fprintf(rw.w, "Hash: %s\n", hashed)
+		}
+	case formatPlain:
+		// A password and its hash are separated by a tab. Hashes never contain a tab, so the last
+		// tab of a line separates them, even if a passphrase uses tabs as its separator.
+		fields := rw.fields(result.Password, hashed)
+		_, err = fmt.Fprintln(rw.w, strings.Join(fields, "\t"))
+	case formatJSON:
+		r := rw.newJSONResult(result)
+		r.Hash = hashed
+		if rw.hashOnly {
+			r.Password = ""
+		}
+		err = json.NewEncoder(rw.w).Encode(r)
+	case formatCSV:
+		r := rw.newJSONResult(result)
+		record := []string{
+			strconv.Itoa(r.Length),
+			strings.Join(r.Classes, "+"),
+			strconv.FormatFloat(r.Entropy, 'f', 2, 64),
+			r.Strength,
+		}
+		err = rw.csv.Write(append(rw.fields(r.Password, hashed), record...))
+		if err == nil {
+			// Flush every record, so the output is streamed as well.
+			rw.csv.Flush()
+			err = rw.csv.Error()
+		}
+	case formatEnv:
+		name := rw.variableName()
+		if !rw.hashOnly {
+			_, err = fmt.Fprintf(rw.w, "%s=%s\n", name, quoteEnv(result.Password))
+		}
+		if err == nil && hashed != "" {
+			_, err = fmt.Fprintf(rw.w, "%s_HASH=%s\n", name, quoteEnv(hashed))
+		}
+	}
+
+	return err
+}
+
+// fields returns the password and its hash if the passwords are hashed, or only one of them.
+func (rw *resultWriter) fields(password, hashed string) []string {
+	switch {
+	case rw.hash.Algorithm == "":
+		return []string{password}
+	case rw.hashOnly:
+		return []string{hashed}
+	}
+	return []string{password, hashed}
+}
+
+// writeHeader writes the lines that precede the passwords of the text and csv formats.
+func (rw *resultWriter) writeHeader(result gofee.Result) error {
+	var err error
+	switch rw.format {
+	case formatText:
+		// All passwords share the same configuration, so the entropy is written only once.
+		_, err = fmt.Fprintf(rw.w, "Entropy: %s\n", color.GreenString("%.2f bits", result.Entropy))
+	case formatCSV:
+		err = rw.csv.Write(append(rw.fields("password", "hash"), "length", "classes", "entropy", "strength"))
+	}
+	return err
+}
+
+// variableName returns the name of the environment variable of the current password.
+// With more than one password, the names are numbered starting at 1.
+func (rw *resultWriter) variableName() string {
+	if rw.count > 1 {
+		return fmt.Sprintf("%s_%d", rw.envName, rw.written)
+	}
+	return rw.envName
+}
+
+// newJSONResult converts a generated password into its JSON representation.
+func (rw *resultWriter) newJSONResult(result gofee.Result) jsonResult {
+	return jsonResult{
+		Password: result.Password,
+		Length:   utf8.RuneCountInString(result.Password),
+		Classes:  charsetClasses(result.Charset, rw.symbols),
+		Entropy:  result.Entropy,
+		Strength: gofee.Strength(result.Entropy),
+	}
+}
+
+// charsetClasses returns the names of the character classes a charset consists of, with the
+// given symbols or gofee.Symbols if they are empty. Passphrases have no charset and are reported
+// as words.
+func charsetClasses(charset, symbols string) []string {
+	if charset == "" {
+		return []string{"words"}
+	}
+	if symbols == "" {
+		symbols = gofee.Symbols
+	}
+
+	classes := []struct {
+		name  string
+		chars string
+	}{
+		{"lowers", gofee.Lowers},
+		{"uppers", gofee.Uppers},
+		{"digits", gofee.Digits},
+		{"symbols", symbols},
+	}
+
+	var names []string
+	for _, class := range classes {
+		if strings.ContainsAny(charset, class.chars) {
+			names = append(names, class.name)
+		}
+	}
+
+	// Custom charsets may contain characters outside of the known classes.
+	known := gofee.Lowers + gofee.Uppers + gofee.Digits + symbols
+	if strings.IndexFunc(charset, func(c rune) bool { return !strings.ContainsRune(known, c) }) >= 0 {
+		names = append(names, "other")
+	}
+	return names
+}
+
+// quoteEnv quotes a value for env files and POSIX shells using single quotes,
+// so no character of the password is interpreted.
+func quoteEnv(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
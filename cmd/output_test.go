@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/fatih/color"
+)
+
+func TestResultWriter(t *testing.T) {
+	color.NoColor = true
+
+	results := []gofee.Result{
+		{Password: "abc123", Charset: gofee.Lowers + gofee.Digits, Entropy: 31.02},
+		{Password: "it's", Charset: gofee.Lowers + gofee.Symbols + "'", Entropy: 31.02},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		count   int
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "Text",
+			format: formatText,
+			count:  2,
+			want:   "Entropy: 31.02 bits\nPassword: abc123\nPassword: it's\n",
+		},
+		{
+			name:   "Plain",
+			format: formatPlain,
+			count:  2,
+			want:   "abc123\nit's\n",
+		},
+		{
+			name:   "JSON",
+			format: formatJSON,
+			count:  2,
+			want: `{"password":"abc123","length":6,"classes":["lowers","digits"],"entropy":31.02,"strength":"weak"}` + "\n" +
+				`{"password":"it's","length":4,"classes":["lowers","symbols","other"],"entropy":31.02,"strength":"weak"}` + "\n",
+		},
+		{
+			name:   "CSV",
+			format: formatCSV,
+			count:  2,
+			want:   "password,length,classes,entropy,strength\nabc123,6,lowers+digits,31.02,weak\nit's,4,lowers+symbols+other,31.02,weak\n",
+		},
+		{
+			name:   "Env with count",
+			format: formatEnv,
+			count:  2,
+			want:   "PASSWORD_1='abc123'\nPASSWORD_2='it'\\''s'\n",
+		},
+		{
+			name:    "Unknown format",
+			format:  "xml",
+			count:   2,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := newResultWriter(&buf, tt.format, "PASSWORD", tt.count)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newResultWriter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			for _, r := range results {
+				if err := w.Write(r); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultWriterEnvSingle(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newResultWriter(&buf, formatEnv, "DB_PASSWORD", 1)
+	if err != nil {
+		t.Fatalf("newResultWriter() error = %v", err)
+	}
+
+	if err := w.Write(gofee.Result{Password: "secret"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if got, want := buf.String(), "DB_PASSWORD='secret'\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestResultWriterHash(t *testing.T) {
+	color.NoColor = true
+
+	// The salt is random, so only the shape of the hash is known.
+	const hash = `\$6\$rounds=1000\$[./A-Za-z0-9]{16}\$[./A-Za-z0-9]{86}`
+
+	tests := []struct {
+		name   string
+		format string
+		only   bool
+		want   string
+	}{
+		{"Text", formatText, false, `^Entropy: 31.02 bits\nPassword: abc123\nHash: ` + hash + `\n$`},
+		{"Text hash only", formatText, true, `^Entropy: 31.02 bits\nHash: ` + hash + `\n$`},
+		{"Plain", formatPlain, false, `^abc123\t` + hash + `\n$`},
+		{"Plain hash only", formatPlain, true, `^` + hash + `\n$`},
+		{"JSON", formatJSON, false, `^\{"password":"abc123","length":6,.*,"hash":"` + hash + `"\}\n$`},
+		{"JSON hash only", formatJSON, true, `^\{"length":6,.*,"hash":"` + hash + `"\}\n$`},
+		{"CSV", formatCSV, false, `^password,hash,length,classes,entropy,strength\nabc123,` + hash + `,6,lowers\+digits,31.02,weak\n$`},
+		{"CSV hash only", formatCSV, true, `^hash,length,classes,entropy,strength\n` + hash + `,6,lowers\+digits,31.02,weak\n$`},
+		{"Env", formatEnv, false, `^PASSWORD='abc123'\nPASSWORD_HASH='` + hash + `'\n$`},
+		{"Env hash only", formatEnv, true, `^PASSWORD_HASH='` + hash + `'\n$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := newResultWriter(&buf, tt.format, "PASSWORD", 1)
+			if err != nil {
+				t.Fatalf("newResultWriter() error = %v", err)
+			}
+			w.setHash(gofee.HashConfig{Algorithm: gofee.HashSHA512Crypt, Rounds: 1000}, tt.only)
+
+			if err := w.Write(gofee.Result{Password: "abc123", Charset: gofee.Lowers + gofee.Digits, Entropy: 31.02}); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if got := buf.String(); !regexp.MustCompile(tt.want).MatchString(got) {
+				t.Errorf("output = %q, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// Nothing is written if the password cannot be hashed.
+	var buf bytes.Buffer
+	w, err := newResultWriter(&buf, formatText, "PASSWORD", 1)
+	if err != nil {
+		t.Fatalf("newResultWriter() error = %v", err)
+	}
+	w.setHash(gofee.HashConfig{Algorithm: "md5-crypt"}, false)
+	if err := w.Write(gofee.Result{Password: "abc123"}); err == nil || buf.Len() != 0 {
+		t.Errorf("Write() = %v with output %q, want an error and no output", err, buf.String())
+	}
+}
+
+func TestResultWriterEnvName(t *testing.T) {
+	for _, name := range []string{"", "1PASSWORD", "DB-PASSWORD", "PASSWORD=x", "$(id)"} {
+		if _, err := newResultWriter(&bytes.Buffer{}, formatEnv, name, 1); err == nil {
+			t.Errorf("newResultWriter() with variable name %q succeeded", name)
+		}
+	}
+
+	// Other formats do not use the name.
+	if _, err := newResultWriter(&bytes.Buffer{}, formatPlain, "", 1); err != nil {
+		t.Errorf("newResultWriter() error = %v", err)
+	}
+}
+
+func TestCharsetClasses(t *testing.T) {
+	tests := []struct {
+		name    string
+		charset string
+		symbols string
+		want    string
+	}{
+		{"Passphrase", "", "", "words"},
+		{"All", gofee.All, "", "lowers uppers digits symbols"},
+		{"Other", "ab€", "", "lowers other"},
+		// Custom symbols are symbols, even outside of the default symbols.
+		{"Custom symbols", "ab€£", "€£", "lowers symbols"},
+		{"Custom symbols and default symbols", "ab€!", "€", "lowers symbols other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(charsetClasses(tt.charset, tt.symbols), " "); got != tt.want {
+				t.Errorf("charsetClasses(%q, %q) = %s, want %s", tt.charset, tt.symbols, got, tt.want)
+			}
+		})
+	}
+}
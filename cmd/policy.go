@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// policyFlags are the flags describing the passwords, which are replaced by a policy.
+var policyFlags = []string{
+	"exclude-lowers", "exclude-uppers", "exclude-digits", "exclude-symbols", "type", "require-all",
+	"min-lowers", "min-uppers", "min-digits", "min-symbols", "charset", "symbols", "exclude-chars",
+	"no-ambiguous", "pattern", "separator", "capitalize", "add-digit", "add-symbol",
+}
+
+func init() {
+	policyCmd.AddCommand(policyValidateCmd)
+	rootCmd.AddCommand(policyCmd)
+}
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Manage password policies",
+	Long: `
+Policies describe the password rules of a target system in a YAML or JSON file, such as
+
+  name: legacy-erp
+  description: Passwords of the old ERP system
+  max_length: 20
+  exclude: "&"
+  first: [letters]
+  min_digits: 2
+
+Named policies are looked up in the policies directory of the configuration directory
+($XDG_CONFIG_HOME/gofee/policies on Linux) as <name>.yaml, <name>.yml or <name>.json.
+Passwords are generated from a policy with gofee --policy <name>.
+
+The supported rules are type, length, min_length, max_length, lowers, uppers, digits, symbols,
+symbol_set, charset, exclude, no_ambiguous, require_all, min_lowers, min_uppers, min_digits,
+min_symbols, first (lowers, uppers, letters, digits or symbols), pattern, and separator,
+capitalize, add_digit and add_symbol for memorable passwords. The length of memorable passwords
+is a number of words, and every passphrase of that many words must fit between min_length and
+max_length, however short or long its words are.
+`,
+}
+
+var policyValidateCmd = &cobra.Command{
+	Use:   "validate NAME|PATH...",
+	Short: "Check that policies can be satisfied and report their entropy",
+	Example: `
+gofee policy validate legacy-erp
+gofee policy validate ./policies/*.yaml
+`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		invalid := 0
+		for _, name := range args {
+			policy, err := loadPolicy(name)
+			var entropy float64
+			if err == nil {
+				entropy, err = policy.Validate(policyDefaultLength(policy))
+			}
+
+			if err != nil {
+				invalid++
+				fmt.Printf("%s: %s: %v\n", name, color.RedString("invalid"), err)
+				continue
+			}
+			fmt.Printf("%s: %s, entropy %.2f bits (%s)\n",
+				policy.Name, color.GreenString("valid"), entropy, gofee.Strength(entropy))
+		}
+
+		if invalid > 0 {
+			return fmt.Errorf("%d of %d policies are invalid", invalid, len(args))
+		}
+		return nil
+	},
+}
+
+// loadPolicy loads a policy by its name from the policy directory, or from a path.
+func loadPolicy(name string) (*gofee.Policy, error) {
+	dir, err := gofee.PolicyDir()
+	if err != nil {
+		return nil, err
+	}
+	path, err := gofee.FindPolicy(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	return gofee.LoadPolicy(path)
+}
+
+// policyConfig returns the length and PasswordConfig of the named policy. A length given on the
+// command line or in the environment replaces the length of the policy, but must satisfy its limits.
+func policyConfig(name string) (int, gofee.PasswordConfig, error) {
+	policy, err := loadPolicy(name)
+	if err != nil {
+		return 0, gofee.PasswordConfig{}, err
+	}
+	if flagSources["length"] >= sourceEnv {
+		policy.Length = options.length
+	}
+
+	length, config, err := policy.Config(policyDefaultLength(policy))
+	if err != nil {
+		return 0, gofee.PasswordConfig{}, fmt.Errorf("invalid policy %s: %v", policy.Name, err)
+	}
+	return length, config, nil
+}
+
+// policyDefaultLength returns the length used if the policy does not fix it, which is a number
+// of words for memorable passwords.
+func policyDefaultLength(policy *gofee.Policy) int {
+	if policy.Type == "memorable" {
+		return defaultWords
+	}
+	return defaultLength
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// writePolicy writes a policy file and returns its path.
+func writePolicy(t *testing.T, name, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRootCmdWithPolicy(t *testing.T) {
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	policy := writePolicy(t, "erp.yaml", "max_length: 10\nsymbols: false\nfirst: [uppers]\nmin_digits: 2\n")
+	rootCmd.SetArgs([]string{"--policy", policy, "--count", "20", "--output", "plain"})
+
+	output, err := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("error executing rootCmd: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	passwords := strings.Fields(output)
+	if len(passwords) != 20 {
+		t.Fatalf("expected 20 passwords, but got %q", output)
+	}
+	for _, password := range passwords {
+		if !regexp.MustCompile(`^[A-Z][a-zA-Z0-9]{9}$`).MatchString(password) || strings.Count(regexp.MustCompile(`[0-9]`).ReplaceAllString(password, "0"), "0") < 2 {
+			t.Errorf("expected a password of the policy, but got %q", password)
+		}
+	}
+}
+
+func TestRootCmdWithPolicyLength(t *testing.T) {
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	// The length flag replaces the length of the policy.
+	policy := writePolicy(t, "pin.json", `{"type": "pin", "length": 4, "max_length": 8}`)
+	rootCmd.SetArgs([]string{"--policy", policy, "--length", "6", "--output", "plain"})
+
+	output, err := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("error executing rootCmd: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	if !regexp.MustCompile(`^[0-9]{6}\n$`).MatchString(output) {
+		t.Errorf("expected a PIN of 6 digits, but got %q", output)
+	}
+}
+
+func TestPolicyValidateCmd(t *testing.T) {
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	valid := writePolicy(t, "erp.yaml", "name: legacy-erp\nmax_length: 20\nexclude: \"&\"\nfirst: [letters]\n")
+	invalid := writePolicy(t, "broken.yaml", "min_length: 20\nmax_length: 10\n")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Valid",
+			args: []string{valid},
+			want: []string{"legacy-erp: valid, entropy", "(strong)"},
+		},
+		{
+			name:    "Invalid",
+			args:    []string{valid, invalid},
+			want:    []string{"legacy-erp: valid", invalid + ": invalid: min_length 20 is greater than max_length 10"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rootCmd.SetArgs(append([]string{"policy", "validate"}, tt.args...))
+
+			var execErr error
+			output, err := captureOutput(func() {
+				execErr = rootCmd.Execute()
+			})
+			if err != nil {
+				t.Fatalf("failed to capture output: %v", err)
+			}
+
+			if (execErr != nil) != tt.wantErr {
+				t.Errorf("expected error %v, but got %v", tt.wantErr, execErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("expected output to contain %q, but got %q", want, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// qrPNGSize is the width and height of QR code images in pixels.
+const qrPNGSize = 512
+
+// writeQR writes a QR code of the content to the terminal. Every line holds two rows of modules
+// as Unicode half blocks. Light modules are drawn, so the code reads as dark on light on the
+// usual dark terminal background.
+func writeQR(w io.Writer, content string) error {
+	q, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("error encoding QR code: %v", err)
+	}
+
+	bitmap := q.Bitmap()
+	for y := 0; y < len(bitmap); y += 2 {
+		line := make([]rune, 0, len(bitmap[y]))
+		for x := range bitmap[y] {
+			top := !bitmap[y][x]
+			bottom := y+1 < len(bitmap) && !bitmap[y+1][x]
+			switch {
+			case top && bottom:
+				line = append(line, '█')
+			case top:
+				line = append(line, '▀')
+			case bottom:
+				line = append(line, '▄')
+			default:
+				line = append(line, ' ')
+			}
+		}
+		if _, err := fmt.Fprintln(w, string(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeQRPNG writes a QR code of the content as a PNG image. The file is only readable by its
+// owner, as it holds a password.
+func writeQRPNG(path, content string) error {
+	q, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("error encoding QR code: %v", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := q.Write(qrPNGSize, f); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing QR code: %v", err)
+	}
+	return f.Close()
+}
+
+// writeQROutput writes the QR code of the content to the terminal, or to a PNG file if path is set.
+func writeQROutput(path, content string) error {
+	if path == "" {
+		return writeQR(os.Stdout, content)
+	}
+	if err := writeQRPNG(path, content); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote the QR code to %s\n", path)
+	return nil
+}
+
+// printQR generates a single password, prints it and its QR code to the terminal, or writes the
+// QR code to a PNG file if path is set.
+func printQR(length int, config gofee.PasswordConfig, path string) error {
+	result, err := gofee.GenerateResult(length, config)
+	if err != nil {
+		return fmt.Errorf("error generating password: %v", err)
+	}
+
+	out, err := newResultWriter(os.Stdout, formatText, "", 1)
+	if err != nil {
+		return err
+	}
+	if err := out.Write(result); err != nil {
+		return err
+	}
+	return writeQROutput(path, result.Password)
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// decodeHalfBlocks turns the lines of a terminal QR code back into rows of dark modules.
+func decodeHalfBlocks(lines []string) [][]bool {
+	var bitmap [][]bool
+	for _, line := range lines {
+		var top, bottom []bool
+		for _, c := range line {
+			top = append(top, c != '█' && c != '▀')
+			bottom = append(bottom, c != '█' && c != '▄')
+		}
+		bitmap = append(bitmap, top, bottom)
+	}
+	return bitmap
+}
+
+func TestWriteQR(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeQR(&buf, "correct horse battery staple"); err != nil {
+		t.Fatalf("writeQR() error = %v", err)
+	}
+
+	q, err := qrcode.New("correct horse battery staple", qrcode.Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := q.Bitmap()
+
+	// The last line of a code with an odd number of rows only holds a top row.
+	got := decodeHalfBlocks(strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"))
+	if len(got) != len(want)+len(want)%2 {
+		t.Fatalf("writeQR() wrote %d rows, want %d", len(got), len(want))
+	}
+	for y := range want {
+		for x := range want[y] {
+			if got[y][x] != want[y][x] {
+				t.Fatalf("writeQR() module (%d, %d) = %v, want %v", x, y, got[y][x], want[y][x])
+			}
+		}
+	}
+}
+
+func TestWriteQRPNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password.png")
+	if err := writeQRPNG(path, "hunter2"); err != nil {
+		t.Fatalf("writeQRPNG() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("writeQRPNG() wrote the file with permissions %v, want 0600", perm)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("writeQRPNG() wrote an invalid PNG: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != qrPNGSize || size.Y != qrPNGSize {
+		t.Errorf("writeQRPNG() wrote an image of %v, want %dx%d", size, qrPNGSize, qrPNGSize)
+	}
+}
+
+func TestRootCmdWithQR(t *testing.T) {
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	rootCmd.SetArgs([]string{"--qr", "--length", "12"})
+
+	output, err := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("error executing rootCmd: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	if !strings.Contains(output, "Password: ") || !strings.Contains(output, "█") {
+		t.Errorf("expected a password and a QR code, but got %q", output)
+	}
+}
+
+func TestRootCmdWithQRPNG(t *testing.T) {
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	path := filepath.Join(t.TempDir(), "password.png")
+	rootCmd.SetArgs([]string{"--qr-png", path})
+
+	output, err := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("error executing rootCmd: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	if !strings.Contains(output, "Wrote the QR code to "+path) || strings.Contains(output, "█") {
+		t.Errorf("expected the QR code to be written to the file, but got %q", output)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected the PNG file to be written: %v", err)
+	}
+}
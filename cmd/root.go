@@ -6,16 +6,20 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/timwehrle/gofee/pkg/gofee"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
 const (
 	// The default length of the password
 	defaultLength int = 16
+	// The default number of words of a memorable password
+	defaultWords int = 6
 
 	MAJOR = 0
 	MINOR = 1
@@ -25,11 +29,38 @@ const (
 // Options for the generate command
 var options struct {
 	length       int
+	count        int
 	lowers       bool
 	uppers       bool
 	digits       bool
 	symbols      bool
 	passwordType string
+	separator    string
+	capitalize   bool
+	addDigit     bool
+	addSymbol    bool
+	requireAll   bool
+	minLowers    int
+	minUppers    int
+	minDigits    int
+	minSymbols   int
+	charset      string
+	symbolSet    string
+	excludeChars string
+	noAmbiguous  bool
+	pattern      string
+	policy       string
+	profile      string
+	clip         bool
+	clipTimeout  time.Duration
+	qr           bool
+	qrPNG        string
+	breachDB     string
+	output       string
+	envName      string
+	noColor      bool
+	hash         gofee.HashConfig
+	hashOnly     bool
 }
 
 func init() {
@@ -38,7 +69,67 @@ func init() {
 	rootCmd.Flags().BoolVarP(&options.digits, "exclude-digits", "d", false, "exclude digits")
 	rootCmd.Flags().BoolVarP(&options.symbols, "exclude-symbols", "s", false, "exclude symbols")
 	rootCmd.Flags().IntVarP(&options.length, "length", "l", defaultLength, "length of the password")
-	rootCmd.Flags().StringVarP(&options.passwordType, "type", "t", "", "type of password to generate (pin, memorable)")
+	rootCmd.Flags().IntVarP(&options.count, "count", "n", 1, "number of passwords to generate")
+	rootCmd.Flags().StringVarP(&options.passwordType, "type", "t", "", "type of password to generate (pin, memorable, pronounceable)")
+	rootCmd.Flags().StringVar(&options.separator, "separator", gofee.DefaultSeparator, "separator between the words of a memorable password")
+	rootCmd.Flags().BoolVar(&options.capitalize, "capitalize", false, "capitalize the words of a memorable password")
+	rootCmd.Flags().BoolVar(&options.addDigit, "add-digit", false, "add a digit to a memorable password")
+	rootCmd.Flags().BoolVar(&options.addSymbol, "add-symbol", false, "add a symbol to a memorable password")
+	rootCmd.Flags().BoolVarP(&options.requireAll, "require-all", "r", false, "require at least one character of every included class")
+	rootCmd.Flags().IntVar(&options.minLowers, "min-lowers", 0, "minimum number of lowercase letters")
+	rootCmd.Flags().IntVar(&options.minUppers, "min-uppers", 0, "minimum number of uppercase letters")
+	rootCmd.Flags().IntVar(&options.minDigits, "min-digits", 0, "minimum number of digits")
+	rootCmd.Flags().IntVar(&options.minSymbols, "min-symbols", 0, "minimum number of symbols")
+	rootCmd.Flags().StringVar(&options.charset, "charset", "", "explicit alphabet to generate the password from")
+	rootCmd.Flags().StringVar(&options.symbolSet, "symbols", "", "symbols to use instead of the default symbols")
+	rootCmd.Flags().StringVarP(&options.excludeChars, "exclude-chars", "x", "", "characters to exclude from the password")
+	rootCmd.Flags().BoolVar(&options.noAmbiguous, "no-ambiguous", false, "exclude visually ambiguous characters ("+gofee.Ambiguous+")")
+	rootCmd.Flags().StringVar(&options.pattern, "pattern", "", "shape of the password, such as Cvccvc-99-ss (see gofee --help)")
+	rootCmd.Flags().StringVar(&options.policy, "policy", "", "name or path of a policy describing the password rules (see gofee policy)")
+	rootCmd.Flags().StringVar(&options.breachDB, "breach-db", "", "regenerate passwords found in a local Pwned Passwords dataset or index")
+	rootCmd.Flags().StringVarP(&options.output, "output", "o", formatText, "output format ("+strings.Join(outputFormats, ", ")+")")
+	rootCmd.Flags().BoolVar(&options.clip, "clip", false, "copy the password to the clipboard instead of printing it")
+	rootCmd.Flags().DurationVar(&options.clipTimeout, "clip-timeout", defaultClipTimeout, "time after which the clipboard is cleared, 0 to keep the password")
+	rootCmd.Flags().BoolVar(&options.qr, "qr", false, "print the password as a QR code")
+	rootCmd.Flags().StringVar(&options.qrPNG, "qr-png", "", "write the password as a QR code to a PNG file")
+	rootCmd.Flags().StringVar(&options.envName, "env-name", "PASSWORD", "variable name used by the env output format")
+	rootCmd.Flags().BoolVar(&options.noColor, "no-color", false, "disable colored output")
+	rootCmd.Flags().StringVar(&options.hash.Algorithm, "hash", "", "print the hash of the password ("+strings.Join(gofee.HashAlgorithms, ", ")+")")
+	rootCmd.Flags().BoolVar(&options.hashOnly, "hash-only", false, "print the hash instead of the password")
+	rootCmd.Flags().IntVar(&options.hash.Cost, "hash-cost", 0, fmt.Sprintf("cost of bcrypt (default %d) or log2 of the scrypt cost (default %d)", gofee.BcryptCost, gofee.ScryptLogN))
+	rootCmd.Flags().IntVar(&options.hash.Rounds, "hash-rounds", 0, fmt.Sprintf("passes of argon2id (default %d), rounds of sha512-crypt (default %d) or iterations of pbkdf2 (default %d)", gofee.Argon2idPasses, gofee.SHA512CryptRounds, gofee.PBKDF2Iterations))
+	rootCmd.Flags().Uint32Var(&options.hash.Memory, "hash-memory", 0, fmt.Sprintf("memory of argon2id in KiB (default %d)", gofee.Argon2idMemory))
+	rootCmd.Flags().IntVar(&options.hash.Parallelism, "hash-parallelism", 0, fmt.Sprintf("lanes of argon2id (default %d) or parallelism of scrypt (default %d)", gofee.Argon2idLanes, gofee.ScryptParallelism))
+
+	// A pattern defines the length and the characters of every position.
+	for _, flag := range []string{"length", "type", "charset", "require-all", "min-lowers", "min-uppers", "min-digits", "min-symbols"} {
+		rootCmd.MarkFlagsMutuallyExclusive("pattern", flag)
+	}
+
+	// A policy replaces the flags describing the passwords.
+	for _, flag := range policyFlags {
+		rootCmd.MarkFlagsMutuallyExclusive("policy", flag)
+	}
+
+	// A copied password is not printed, and the clipboard holds a single one.
+	rootCmd.MarkFlagsMutuallyExclusive("clip", "count")
+	rootCmd.MarkFlagsMutuallyExclusive("clip", "output")
+	rootCmd.MarkFlagsMutuallyExclusive("clip", "env-name")
+
+	// A QR code holds a single password and is written next to the text output.
+	for _, flag := range []string{"qr", "qr-png"} {
+		for _, other := range []string{"count", "output", "env-name", "clip"} {
+			rootCmd.MarkFlagsMutuallyExclusive(flag, other)
+		}
+	}
+	rootCmd.MarkFlagsMutuallyExclusive("qr", "qr-png")
+
+	// Hashes are only printed, never copied or encoded as QR codes.
+	for _, flag := range []string{"clip", "qr", "qr-png"} {
+		for _, other := range []string{"hash", "hash-only"} {
+			rootCmd.MarkFlagsMutuallyExclusive(flag, other)
+		}
+	}
 
 	// Colorize the usage output
 	rootCmd.SetOutput(color.Output)
@@ -60,11 +151,38 @@ var example = `
 gofee --length 20 --exclude-lowers
 gofee --length 12 -u -d 
 gofee --type pin --length 4
+gofee --type memorable --length 5 --capitalize --add-digit
+gofee --type pronounceable --length 12 --require-all
+gofee --length 12 --require-all --min-digits 2
+gofee --count 100 --length 24 --output plain
+gofee --exclude-chars '"<>\' --symbols '!#%+=?'
+gofee --charset abcdef0123456789 --length 32
+gofee --no-ambiguous --exclude-symbols
+gofee --output env --env-name DB_PASSWORD
+gofee --pattern 'Cvccvc-99-ss'
+gofee --pattern 'u{2}d{4}s' --symbols '!#%'
+gofee --policy legacy-erp --count 5
+gofee -p wifi
+gofee --clip --clip-timeout 30s
+gofee --type memorable --qr
+gofee wifi --ssid Guest --png guest-wifi.png
+gofee --length 12 --breach-db pwned-passwords.idx
+gofee --hash argon2id --output json
+gofee --hash bcrypt --hash-cost 14 --hash-only
 `
 
 var long = `
 Gofee is a simple password generator, which relies on the cryptographic strength of the system's random number generator.
 It generates a password of a given length, using a set of characters that can be customized by the user.
+
+Passwords of a fixed shape are generated with --pattern, where every token stands for one character:
+  l  lowercase letter       u  uppercase letter       a  letter
+  c  lowercase consonant    C  uppercase consonant    x  letter or digit
+  v  lowercase vowel        V  uppercase vowel        h  lowercase hex digit
+  d  digit (also 9)         s  symbol                 H  uppercase hex digit
+  *  letter, digit or symbol
+A token followed by {n} is repeated n times, a backslash takes the next character literally,
+and other characters such as - or . are copied as they are.
 `
 
 var rootCmd = &cobra.Command{
@@ -74,29 +192,124 @@ var rootCmd = &cobra.Command{
 	Short:   "Gofee is a simple password generator, which is reliable and secure.",
 	Long:    long,
 	Run: func(cmd *cobra.Command, args []string) {
+		// The hash flags are checked first, so no plaintext password is output by mistake.
+		if err := checkHashFlags(); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+
 		config := gofee.PasswordConfig{
 			IncludeLowers:  !options.lowers,
 			IncludeUppers:  !options.uppers,
 			IncludeDigits:  !options.digits,
 			IncludeSymbols: !options.symbols,
 			Type:           options.passwordType,
+			Passphrase: gofee.PassphraseConfig{
+				Separator:  options.separator,
+				Capitalize: options.capitalize,
+				AddDigit:   options.addDigit,
+				AddSymbol:  options.addSymbol,
+			},
+			RequireAll: options.requireAll,
+			MinLowers:  options.minLowers,
+			MinUppers:  options.minUppers,
+			MinDigits:  options.minDigits,
+			MinSymbols: options.minSymbols,
+
+			CustomCharset: options.charset,
+			CustomSymbols: options.symbolSet,
+			ExcludeChars:  options.excludeChars,
+
+			ExcludeAmbiguous: options.noAmbiguous,
+
+			Pattern: options.pattern,
 		}
 
-		pw, err := gofee.Generate(options.length, config)
+		// The length of a memorable password is its number of words. A length configured below
+		// the type is meant for other passwords.
+		length := options.length
+		if config.Type == "memorable" && (flagSources["length"] == sourceDefault || flagSources["length"] < flagSources["type"]) {
+			length = defaultWords
+		}
+
+		// A policy describes the passwords instead of the flags.
+		if options.policy != "" {
+			var err error
+			length, config, err = policyConfig(options.policy)
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		}
+
+		// Passwords found in the breach database are generated again.
+		if options.breachDB != "" {
+			db, err := gofee.OpenBreachDB(options.breachDB)
+			if err != nil {
+				log.Fatalf("Error opening breach database: %v", err)
+			}
+			defer db.Close()
+			config.BreachDB = db
+		}
+
+		// Colors are only useful for humans looking at a terminal.
+		if options.noColor || !isTerminal(os.Stdout) {
+			color.NoColor = true
+		}
+
+		if options.clip {
+			if err := copyPassword(length, config, options.clipTimeout); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+			return
+		}
+
+		if options.qr || options.qrPNG != "" {
+			if err := printQR(length, config, options.qrPNG); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+			return
+		}
+
+		out, err := newResultWriter(os.Stdout, options.output, options.envName, options.count)
 		if err != nil {
-			log.Fatalf("Error generating password: %v", err)
+			log.Fatalf("Error: %v", err)
 		}
+		out.setHash(options.hash, options.hashOnly)
+		out.setSymbols(config.CustomSymbols)
 
-		entropy, err := gofee.CalculateEntropy(len(gofee.Charset), options.length)
+		// Write every password as soon as it is generated.
+		err = gofee.GenerateN(options.count, length, config, out.Write)
 		if err != nil {
-			log.Fatalf("Error calculating entropy: %v", err)
+			log.Fatalf("Error generating password: %v", err)
 		}
+	},
+}
 
-		fmt.Print("Entropy: ")
-		color.Green("%.2f bits", entropy)
+// checkHashFlags returns an error if a flag configuring the hash is set without --hash.
+func checkHashFlags() error {
+	if options.hash.Algorithm != "" {
+		return nil
+	}
+	flags := []struct {
+		name string
+		set  bool
+	}{
+		{"hash-only", options.hashOnly},
+		{"hash-cost", options.hash.Cost != 0},
+		{"hash-rounds", options.hash.Rounds != 0},
+		{"hash-memory", options.hash.Memory != 0},
+		{"hash-parallelism", options.hash.Parallelism != 0},
+	}
+	for _, flag := range flags {
+		if flag.set {
+			return fmt.Errorf("--%s requires --hash", flag.name)
+		}
+	}
+	return nil
+}
 
-		fmt.Printf("Password: %s", color.GreenString(pw))
-	},
+// isTerminal reports whether the file is a terminal.
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
 }
 
 func Execute() {
@@ -3,9 +3,46 @@ package cmd
 import (
 	"bytes"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+// testConfigHome is an empty configuration directory, so the tests do not read the
+// configuration file of the machine they run on.
+var testConfigHome string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "gofee-test")
+	if err != nil {
+		panic(err)
+	}
+	testConfigHome = dir
+
+	// Configuration set in the environment would change the defaults as well.
+	for _, env := range os.Environ() {
+		if name, _, _ := strings.Cut(env, "="); strings.HasPrefix(name, envPrefix) {
+			os.Unsetenv(name)
+		}
+	}
+	isolateConfig()
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// isolateConfig points the configuration directory to testConfigHome. HOME is set as well,
+// as os.UserConfigDir ignores XDG_CONFIG_HOME on macOS and Windows.
+func isolateConfig() {
+	os.Setenv("XDG_CONFIG_HOME", testConfigHome)
+	os.Setenv("HOME", testConfigHome)
+	os.Setenv("AppData", testConfigHome)
+}
+
 func captureOutput(f func()) (string, error) {
 	old := os.Stdout
 	r, w, err := os.Pipe()
@@ -59,3 +96,118 @@ func TestRootCmdWithFlags(t *testing.T) {
 		t.Errorf("expected output to contain password, but got %q", output)
 	}
 }
+
+// resetFlags restores the default values of all flags of the root command and its subcommands,
+// and the empty configuration directory.
+func resetFlags(t *testing.T) {
+	t.Helper()
+
+	reset := func(f *pflag.Flag) {
+		var err error
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			// Setting a slice flag appends to it, so its values are replaced instead.
+			err = slice.Replace(nil)
+		} else {
+			err = f.Value.Set(f.DefValue)
+		}
+		if err != nil {
+			t.Fatalf("failed to reset flag %s: %v", f.Name, err)
+		}
+		f.Changed = false
+	}
+
+	rootCmd.Flags().VisitAll(reset)
+	rootCmd.PersistentFlags().VisitAll(reset)
+	isolateConfig()
+
+	// Reset the subcommands of subcommands as well, such as otp new.
+	var resetCommands func(cmd *cobra.Command)
+	resetCommands = func(cmd *cobra.Command) {
+		for _, sub := range cmd.Commands() {
+			sub.Flags().VisitAll(reset)
+			resetCommands(sub)
+		}
+	}
+	resetCommands(rootCmd)
+}
+
+func TestRootCmdWithCount(t *testing.T) {
+	defer resetFlags(t)
+	rootCmd.SetArgs([]string{"--count", "5", "--length", "12"})
+
+	output, err := captureOutput(func() {
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatalf("error executing rootCmd: %v", err)
+		}
+	})
+
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	if got := strings.Count(output, "Password:"); got != 5 {
+		t.Errorf("expected 5 passwords, but got %d in %q", got, output)
+	}
+}
+
+func TestRootCmdWithPattern(t *testing.T) {
+	defer resetFlags(t)
+	rootCmd.SetArgs([]string{"--pattern", `u{2}d{4}\-s`, "--symbols", "!", "--output", "plain"})
+
+	output, err := captureOutput(func() {
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatalf("error executing rootCmd: %v", err)
+		}
+	})
+
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	if !regexp.MustCompile(`^[A-Z]{2}[0-9]{4}-!\n$`).MatchString(output) {
+		t.Errorf("expected a password of the pattern, but got %q", output)
+	}
+}
+
+func TestRootCmdWithPronounceable(t *testing.T) {
+	defer resetFlags(t)
+	rootCmd.SetArgs([]string{"--type", "pronounceable", "--length", "10", "--require-all", "--output", "plain"})
+
+	output, err := captureOutput(func() {
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatalf("error executing rootCmd: %v", err)
+		}
+	})
+
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	password := strings.TrimSuffix(output, "\n")
+	if len(password) != 10 || !regexp.MustCompile(`^[a-z0-9]*[A-Z][a-z0-9]*$`).MatchString(password) || !strings.ContainsAny(password, "0123456789") {
+		t.Errorf("expected a pronounceable password with one uppercase letter and a digit, but got %q", output)
+	}
+}
+
+func TestRootCmdWithUnicodeCharset(t *testing.T) {
+	defer resetFlags(t)
+	rootCmd.SetArgs([]string{"--charset", "äöüßжя😀", "--length", "10", "--output", "plain"})
+
+	output, err := captureOutput(func() {
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatalf("error executing rootCmd: %v", err)
+		}
+	})
+
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	if !regexp.MustCompile(`^[äöüßжя😀]{10}\n$`).MatchString(output) {
+		t.Errorf("expected 10 characters of the charset, but got %q", output)
+	}
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// Options for the token command
+var tokenOptions struct {
+	format  string
+	size    int
+	prefix  string
+	count   int
+	output  string
+	noColor bool
+}
+
+// jsonToken is the JSON representation of a generated token.
+type jsonToken struct {
+	Token   string  `json:"token"`
+	Format  string  `json:"format"`
+	Entropy float64 `json:"entropy"`
+}
+
+func init() {
+	tokenCmd.Flags().StringVarP(&tokenOptions.format, "format", "f", gofee.TokenHex, "format of the token ("+strings.Join(gofee.TokenFormats, ", ")+")")
+	tokenCmd.Flags().IntVarP(&tokenOptions.size, "bytes", "b", gofee.TokenSize, "number of random bytes of the token, ignored by UUIDs")
+	tokenCmd.Flags().StringVar(&tokenOptions.prefix, "prefix", gofee.APIKeyPrefix, "prefix of API keys (lowercase letters and digits)")
+	tokenCmd.Flags().IntVarP(&tokenOptions.count, "count", "n", 1, "number of tokens to generate")
+	tokenCmd.Flags().StringVarP(&tokenOptions.output, "output", "o", formatText, "output format ("+formatText+", "+formatPlain+", "+formatJSON+")")
+	tokenCmd.Flags().BoolVar(&tokenOptions.noColor, "no-color", false, "disable colored output")
+
+	rootCmd.AddCommand(tokenCmd)
+}
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Generate API keys, random tokens and UUIDs",
+	Long: `
+Generates secrets which are not typed by people, such as API keys, session tokens or signing
+secrets. The entropy of a token is the number of its random bits.
+
+Formats:
+  hex        lowercase hexadecimal
+  base32     Base32 without padding
+  base64     Base64 with padding
+  base64url  URL-safe Base64 without padding
+  urlsafe    letters and digits, safe in URLs, file names and shells
+  apikey     prefixed letters and digits with a CRC32 checksum, such as gof_...
+  uuid4      random UUID
+  uuid7      time-ordered UUID, which sorts by creation
+
+The last 6 characters of an API key are a checksum of the rest, so scanners and services can
+reject mistyped or made-up keys without looking them up.
+`,
+	Example: `
+gofee token
+gofee token --format base64url --bytes 16
+gofee token --format apikey --prefix acme
+gofee token --format uuid7 --count 5 --output plain
+`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch tokenOptions.output {
+		case formatText, formatPlain, formatJSON:
+		default:
+			return fmt.Errorf("unknown output format %q (supported: %s, %s, %s)", tokenOptions.output, formatText, formatPlain, formatJSON)
+		}
+		if tokenOptions.size <= 0 {
+			return fmt.Errorf("bytes must be greater than 0")
+		}
+		if tokenOptions.count <= 0 {
+			return fmt.Errorf("count must be greater than 0")
+		}
+
+		if tokenOptions.noColor || !isTerminal(os.Stdout) {
+			color.NoColor = true
+		}
+
+		config := gofee.TokenConfig{Format: tokenOptions.format, Size: tokenOptions.size, Prefix: tokenOptions.prefix}
+		for i := 0; i < tokenOptions.count; i++ {
+			result, err := gofee.GenerateToken(config)
+			if err != nil {
+				return fmt.Errorf("error generating token: %v", err)
+			}
+			if err := writeToken(os.Stdout, tokenOptions.output, config.Format, result, i == 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// writeToken writes a token in the text, plain or json format. The text format writes the
+// entropy before the first token, as all tokens share it.
+func writeToken(w io.Writer, format, tokenFormat string, result gofee.Result, first bool) error {
+	var err error
+	switch format {
+	case formatText:
+		if first {
+			if _, err = fmt.Fprintf(w, "Entropy: %s\n", color.GreenString("%.2f bits", result.Entropy)); err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(w, "Token: %s\n", color.GreenString(result.Password))
+	case formatPlain:
+		_, err = fmt.Fprintln(w, result.Password)
+	case formatJSON:
+		err = json.NewEncoder(w).Encode(jsonToken{Token: result.Password, Format: tokenFormat, Entropy: result.Entropy})
+	}
+	return err
+}
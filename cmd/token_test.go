@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+)
+
+// executeToken runs the token command with the given arguments and returns its output.
+func executeToken(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	rootCmd.SetArgs(append([]string{"token"}, args...))
+
+	var execErr error
+	output, err := captureOutput(func() {
+		execErr = rootCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+	return output, execErr
+}
+
+func TestTokenCmd(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"Default", nil, `^Entropy: 256\.00 bits\nToken: [0-9a-f]{64}\n$`},
+		{"Base64url", []string{"--format", "base64url", "--bytes", "16"}, `^Entropy: 128\.00 bits\nToken: [A-Za-z0-9_-]{22}\n$`},
+		{"API key", []string{"--format", "apikey", "--prefix", "acme", "--output", "plain"}, `^acme_[A-Za-z0-9]{49}\n$`},
+		{"UUIDs", []string{"-f", "uuid4", "-n", "3", "-o", "plain"}, `^([0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}\n){3}$`},
+		{"Count", []string{"--format", "base32", "--count", "2"}, `^Entropy: 256\.00 bits\nToken: [A-Z2-7]{52}\nToken: [A-Z2-7]{52}\n$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := executeToken(t, append(tt.args, "--no-color")...)
+			if err != nil {
+				t.Fatalf("error executing token: %v", err)
+			}
+			if !regexp.MustCompile(tt.want).MatchString(output) {
+				t.Errorf("expected output matching %s, but got %q", tt.want, output)
+			}
+		})
+	}
+}
+
+func TestTokenCmdJSON(t *testing.T) {
+	output, err := executeToken(t, "--format", "apikey", "--output", "json")
+	if err != nil {
+		t.Fatalf("error executing token: %v", err)
+	}
+
+	var got jsonToken
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("failed to parse %q: %v", output, err)
+	}
+	if got.Format != gofee.TokenAPIKey || !gofee.CheckAPIKey(got.Token) || got.Entropy < 256 {
+		t.Errorf("expected an API key with a valid checksum, but got %+v", got)
+	}
+}
+
+func TestTokenCmdErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"Unknown format", []string{"--format", "base58"}, "unknown token format"},
+		{"Zero bytes", []string{"--bytes", "0"}, "bytes"},
+		{"Zero count", []string{"--count", "0"}, "count"},
+		{"Invalid prefix", []string{"--format", "apikey", "--prefix", "Acme"}, "prefix"},
+		{"Unknown output", []string{"--output", "csv"}, "output format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executeToken(t, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
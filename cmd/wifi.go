@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/timwehrle/gofee/pkg/gofee"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// defaultWiFiLength is the default length of Wi-Fi passphrases.
+const defaultWiFiLength = 20
+
+var wifiOptions struct {
+	ssid        string
+	length      int
+	hidden      bool
+	symbols     bool
+	noAmbiguous bool
+	png         string
+}
+
+func init() {
+	wifiCmd.Flags().StringVar(&wifiOptions.ssid, "ssid", "", "name of the network")
+	wifiCmd.Flags().IntVarP(&wifiOptions.length, "length", "l", defaultWiFiLength, fmt.Sprintf("length of the passphrase (%d to %d)", gofee.WPAMinLength, gofee.WPAMaxLength))
+	wifiCmd.Flags().BoolVar(&wifiOptions.hidden, "hidden", false, "the network does not broadcast its SSID")
+	wifiCmd.Flags().BoolVar(&wifiOptions.symbols, "exclude-symbols", false, "exclude symbols from the passphrase")
+	wifiCmd.Flags().BoolVar(&wifiOptions.noAmbiguous, "no-ambiguous", false, "exclude visually ambiguous characters ("+gofee.Ambiguous+")")
+	wifiCmd.Flags().StringVar(&wifiOptions.png, "png", "", "write the QR code to a PNG file instead of the terminal")
+	_ = wifiCmd.MarkFlagRequired("ssid")
+
+	rootCmd.AddCommand(wifiCmd)
+}
+
+var wifiCmd = &cobra.Command{
+	Use:   "wifi",
+	Short: "Generate a WPA passphrase and a QR code to join the network",
+	Long: `
+Generates a passphrase for a WPA network and prints a QR code, which phones scan to join the
+network. WPA passphrases have 8 to 63 printable ASCII characters.
+`,
+	Example: `
+gofee wifi --ssid Guest
+gofee wifi --ssid Kiosk --length 32 --exclude-symbols --png kiosk-wifi.png
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if wifiOptions.length < gofee.WPAMinLength || wifiOptions.length > gofee.WPAMaxLength {
+			log.Fatalf("Error: length must be between %d and %d for WPA", gofee.WPAMinLength, gofee.WPAMaxLength)
+		}
+
+		result, err := gofee.GenerateResult(wifiOptions.length, gofee.PasswordConfig{
+			IncludeLowers:    true,
+			IncludeUppers:    true,
+			IncludeDigits:    true,
+			IncludeSymbols:   !wifiOptions.symbols,
+			ExcludeAmbiguous: wifiOptions.noAmbiguous,
+		})
+		if err != nil {
+			log.Fatalf("Error generating password: %v", err)
+		}
+
+		payload, err := gofee.WiFiPayload(wifiOptions.ssid, result.Password, wifiOptions.hidden)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+
+		fmt.Printf("SSID: %s\n", wifiOptions.ssid)
+		fmt.Printf("Password: %s\n", color.GreenString(result.Password))
+		fmt.Printf("Entropy: %s\n", color.GreenString("%.2f bits", result.Entropy))
+		if err := writeQROutput(wifiOptions.png, payload); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	},
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWiFiCmd(t *testing.T) {
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	path := filepath.Join(t.TempDir(), "wifi.png")
+	rootCmd.SetArgs([]string{"wifi", "--ssid", "Guest", "--length", "24", "--exclude-symbols", "--png", path})
+
+	output, err := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("error executing wifi: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	if !regexp.MustCompile(`^SSID: Guest\nPassword: [a-zA-Z0-9]{24}\nEntropy: [0-9.]+ bits\nWrote the QR code to `).MatchString(output) {
+		t.Errorf("expected the SSID, password and QR code file, but got %q", output)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected the PNG file to be written: %v", err)
+	}
+}
+
+func TestWiFiCmdTerminal(t *testing.T) {
+	defer resetFlags(t)
+	defer rootCmd.SetArgs(nil)
+
+	rootCmd.SetArgs([]string{"wifi", "--ssid", "Guest", "--hidden"})
+
+	output, err := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("error executing wifi: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to capture output: %v", err)
+	}
+
+	if !strings.Contains(output, "SSID: Guest") || !strings.Contains(output, "█") {
+		t.Errorf("expected the SSID and a QR code, but got %q", output)
+	}
+}
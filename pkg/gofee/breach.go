@@ -0,0 +1,264 @@
+package gofee
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BreachDB looks up passwords in a corpus of breached passwords, such as the
+// Pwned Passwords dataset of Have I Been Pwned.
+type BreachDB interface {
+	// Breaches returns how often the password appears in the corpus, 0 if it does not.
+	Breaches(password string) (int, error)
+}
+
+// LocalBreachDB is a BreachDB reading a locally downloaded Pwned Passwords dataset.
+// Passwords are looked up by their SHA-1 hash and never leave the machine.
+// It is safe for concurrent use.
+type LocalBreachDB struct {
+	source breachSource
+}
+
+// breachSource counts the occurrences of a SHA-1 hash in one of the supported file formats.
+type breachSource interface {
+	count(hash [sha1.Size]byte) (int, error)
+	Close() error
+}
+
+// OpenBreachDB opens a Pwned Passwords dataset at path, which is one of
+//   - a directory of range files named after the first five hex digits of the SHA-1 hash
+//     (such as 21BD1.txt), each containing lines of the remaining 35 hex digits and a count,
+//     as served by the range API and written by the official downloader,
+//   - a text file of full SHA-1 hashes and counts ("HASH:COUNT" lines) ordered by hash,
+//   - a compact index built from one of the above by BuildBreachIndex.
+func OpenBreachDB(path string) (*LocalBreachDB, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &LocalBreachDB{source: breachRangeDir(path)}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Indexes are recognized by their magic number, everything else must be a hash file.
+	magic := make([]byte, len(breachIndexMagic))
+	if _, err := f.ReadAt(magic, 0); err == nil && string(magic) == breachIndexMagic {
+		index, err := openBreachIndex(f, info.Size())
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("invalid breach index %s: %v", path, err)
+		}
+		return &LocalBreachDB{source: index}, nil
+	}
+
+	return &LocalBreachDB{source: &breachHashFile{f: f, size: info.Size()}}, nil
+}
+
+// Breaches returns how often the password appears in the dataset, 0 if it does not.
+func (db *LocalBreachDB) Breaches(password string) (int, error) {
+	return db.source.count(sha1.Sum([]byte(password)))
+}
+
+// Close closes the files of the dataset.
+func (db *LocalBreachDB) Close() error {
+	return db.source.Close()
+}
+
+// parseBreachLine parses a "HEX:COUNT" line of a hash or range file.
+// The hex digits must have the given length and are returned uppercase.
+func parseBreachLine(line string, hexDigits int) (string, int, error) {
+	line = strings.TrimRight(line, "\r\n")
+	hash, countText, found := strings.Cut(line, ":")
+	if !found || len(hash) != hexDigits {
+		return "", 0, fmt.Errorf("invalid line %q", line)
+	}
+	if strings.Trim(hash, "0123456789ABCDEFabcdef") != "" {
+		return "", 0, fmt.Errorf("invalid hash in line %q", line)
+	}
+	count, err := strconv.Atoi(countText)
+	if err != nil || count < 0 {
+		return "", 0, fmt.Errorf("invalid count in line %q", line)
+	}
+	return strings.ToUpper(hash), count, nil
+}
+
+// breachRangeDir is a directory of range files, one per 5 hex digit prefix.
+type breachRangeDir string
+
+// breachPrefixDigits is the number of hex digits of the hash, which name a range file.
+const breachPrefixDigits = 5
+
+// count scans the range file of the hash's prefix for its suffix.
+func (dir breachRangeDir) count(hash [sha1.Size]byte) (int, error) {
+	hexHash := strings.ToUpper(hex.EncodeToString(hash[:]))
+	prefix, suffix := hexHash[:breachPrefixDigits], hexHash[breachPrefixDigits:]
+
+	f, err := os.Open(filepath.Join(string(dir), prefix+".txt"))
+	if err != nil {
+		return 0, fmt.Errorf("no range file for prefix %s: %v", prefix, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+		lineSuffix, count, err := parseBreachLine(scanner.Text(), sha1.Size*2-breachPrefixDigits)
+		if err != nil {
+			return 0, fmt.Errorf("range file %s: %v", prefix, err)
+		}
+		if lineSuffix == suffix {
+			return count, nil
+		}
+	}
+	return 0, scanner.Err()
+}
+
+// Close does nothing, as range files are only opened during a lookup.
+func (dir breachRangeDir) Close() error {
+	return nil
+}
+
+// breachHashFile is a text file of "HASH:COUNT" lines ordered by hash, which is
+// searched by binary search over the byte offsets of the file.
+type breachHashFile struct {
+	f    *os.File
+	size int64
+}
+
+// maxBreachLine is the maximum length of a line of a hash file (a hash, a count and a line break).
+const maxBreachLine = 64
+
+// count returns the count of the first line whose hash is not less than the hash, if they are equal.
+func (h *breachHashFile) count(hash [sha1.Size]byte) (int, error) {
+	target := strings.ToUpper(hex.EncodeToString(hash[:]))
+
+	var searchErr error
+	offset := sort.Search(int(h.size), func(i int) bool {
+		line, err := h.lineAt(int64(i))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return line == "" || strings.ToUpper(line[:len(target)]) >= target
+	})
+	if searchErr != nil {
+		return 0, searchErr
+	}
+
+	line, err := h.lineAt(int64(offset))
+	if err != nil || line == "" {
+		return 0, err
+	}
+	lineHash, count, err := parseBreachLine(line, len(target))
+	if err != nil {
+		return 0, err
+	}
+	if lineHash != target {
+		return 0, nil
+	}
+	return count, nil
+}
+
+// lineAt returns the first line starting at or after offset, or "" at the end of the file.
+func (h *breachHashFile) lineAt(offset int64) (string, error) {
+	// Read from the byte before the offset, so a line starting at the offset is found as well.
+	start := offset
+	if start > 0 {
+		start--
+	}
+	buf := make([]byte, 2*maxBreachLine)
+	n, err := h.f.ReadAt(buf, start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	buf = buf[:n]
+
+	if offset > 0 {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			return "", nil
+		}
+		buf = buf[i+1:]
+	}
+	if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+		buf = buf[:i]
+	}
+
+	line := strings.TrimRight(string(buf), "\r")
+	if line == "" {
+		return "", nil
+	}
+	if len(line) < sha1.Size*2 {
+		return "", fmt.Errorf("invalid line %q at offset %d", line, offset)
+	}
+	return line, nil
+}
+
+// Close closes the hash file.
+func (h *breachHashFile) Close() error {
+	return h.f.Close()
+}
+
+// maxBreachAttempts is the number of passwords BreachCheckedGenerator generates before giving up.
+// Unless the passwords are very short, the first password is almost never found in a breach.
+const maxBreachAttempts = 100
+
+// BreachCheckedGenerator wraps a PasswordGenerator and generates passwords again
+// as long as they are found in a BreachDB.
+type BreachCheckedGenerator struct {
+	pg PasswordGenerator
+	db BreachDB
+}
+
+// Generate returns a new random password, which is not found in the breach database.
+// It returns an error if the lookup fails or maxBreachAttempts passwords in a row were breached.
+func (b *BreachCheckedGenerator) Generate() (string, error) {
+	for i := 0; i < maxBreachAttempts; i++ {
+		password, err := b.pg.Generate()
+		if err != nil {
+			return "", err
+		}
+
+		breaches, err := b.db.Breaches(password)
+		if err != nil {
+			return "", fmt.Errorf("error checking password for breaches: %v", err)
+		}
+		if breaches == 0 {
+			return password, nil
+		}
+	}
+	return "", fmt.Errorf("no password outside of the breach database after %d attempts, try a longer password", maxBreachAttempts)
+}
+
+// Entropy returns the entropy of the wrapped PasswordGenerator, which is an upper bound.
+// Rejecting the breached passwords lowers the entropy by an amount that depends on how many of
+// the possible passwords are in the database. For PINs and other short passwords, most of them
+// may be, so the actual entropy can be much lower.
+func (b *BreachCheckedGenerator) Entropy() float64 {
+	return b.pg.Entropy()
+}
+
+// Charset returns the charset of the wrapped PasswordGenerator, if it has one.
+func (b *BreachCheckedGenerator) Charset() string {
+	if c, ok := b.pg.(interface{ Charset() string }); ok {
+		return c.Charset()
+	}
+	return ""
+}
@@ -0,0 +1,267 @@
+package gofee
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A breach index stores the hashes of a Pwned Passwords dataset in a compact binary form:
+//
+//	magic   8 bytes    "GOFEEHB1"
+//	fanout  65537 * 8  big endian uint64, fanout[i] is the number of records whose
+//	                   hash starts with two bytes less than i
+//	records n * 22     the remaining 18 bytes of the hash and a big endian uint32 count,
+//	                   ordered by hash
+//
+// The fanout table narrows a lookup down to a few thousand records, which are binary searched.
+const (
+	breachIndexMagic    = "GOFEEHB1"
+	breachFanoutSize    = 1<<16 + 1
+	breachHeaderSize    = len(breachIndexMagic) + breachFanoutSize*8
+	breachSuffixSize    = sha1.Size - 2
+	breachRecordSize    = breachSuffixSize + 4
+	maxBreachIndexCount = 1<<32 - 1
+)
+
+// breachIndex is an opened breach index.
+type breachIndex struct {
+	f      *os.File
+	fanout []uint64
+}
+
+// openBreachIndex reads the fanout table of the index and checks it against the file size.
+func openBreachIndex(f *os.File, size int64) (*breachIndex, error) {
+	header := make([]byte, breachHeaderSize)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("error reading header: %v", err)
+	}
+
+	fanout := make([]uint64, breachFanoutSize)
+	for i := range fanout {
+		fanout[i] = binary.BigEndian.Uint64(header[len(breachIndexMagic)+i*8:])
+		if i > 0 && fanout[i] < fanout[i-1] {
+			return nil, fmt.Errorf("corrupt fanout table")
+		}
+	}
+
+	if want := int64(breachHeaderSize) + int64(fanout[breachFanoutSize-1])*breachRecordSize; size != want {
+		return nil, fmt.Errorf("size is %d bytes, want %d", size, want)
+	}
+
+	return &breachIndex{f: f, fanout: fanout}, nil
+}
+
+// count binary searches the records of the hash's fanout bucket.
+func (index *breachIndex) count(hash [sha1.Size]byte) (int, error) {
+	bucket := int(hash[0])<<8 | int(hash[1])
+	lo, hi := index.fanout[bucket], index.fanout[bucket+1]
+
+	record := make([]byte, breachRecordSize)
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if _, err := index.f.ReadAt(record, int64(breachHeaderSize)+int64(mid)*breachRecordSize); err != nil {
+			return 0, fmt.Errorf("error reading breach index: %v", err)
+		}
+
+		switch c := bytes.Compare(record[:breachSuffixSize], hash[2:]); {
+		case c == 0:
+			return int(binary.BigEndian.Uint32(record[breachSuffixSize:])), nil
+		case c < 0:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, nil
+}
+
+// Close closes the index file.
+func (index *breachIndex) Close() error {
+	return index.f.Close()
+}
+
+// BuildBreachIndex builds a compact breach index at dest from the Pwned Passwords dataset at
+// source, which is a directory of range files or a hash file ordered by hash (see OpenBreachDB).
+// Counts larger than 4294967295 are capped. The index is written to a temporary file, which
+// replaces dest only if the whole dataset was read successfully. BuildBreachIndex returns the
+// number of hashes in the index.
+func BuildBreachIndex(source, dest string) (uint64, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), filepath.Base(dest)+".tmp*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		// Remove the temporary file on errors; after the rename this fails harmlessly.
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	w := newBreachIndexWriter(tmp)
+	if info.IsDir() {
+		err = readBreachRangeDir(source, w.add)
+	} else {
+		err = readBreachHashFile(source, w.add)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := w.finish(); err != nil {
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return 0, err
+	}
+	return w.records, nil
+}
+
+// breachIndexWriter writes the records of a breach index as they arrive and the header at the end.
+type breachIndexWriter struct {
+	f       *os.File
+	w       *bufio.Writer
+	buckets []uint64
+	records uint64
+	last    []byte
+}
+
+// newBreachIndexWriter returns a writer for records, which are written after the space for the header.
+func newBreachIndexWriter(f *os.File) *breachIndexWriter {
+	w := bufio.NewWriterSize(f, 1<<20)
+	return &breachIndexWriter{f: f, w: w, buckets: make([]uint64, breachFanoutSize-1)}
+}
+
+// add writes a record. The hashes must be added in strictly ascending order.
+func (iw *breachIndexWriter) add(hash []byte, count int) error {
+	if iw.last != nil && bytes.Compare(hash, iw.last) <= 0 {
+		return fmt.Errorf("hashes are not ordered: %X follows %X", hash, iw.last)
+	}
+	iw.last = append(iw.last[:0], hash...)
+
+	if iw.records == 0 {
+		// Leave space for the header, which is known only at the end.
+		if _, err := iw.w.Write(make([]byte, breachHeaderSize)); err != nil {
+			return err
+		}
+	}
+
+	if uint64(count) > maxBreachIndexCount {
+		count = maxBreachIndexCount
+	}
+
+	record := make([]byte, breachRecordSize)
+	copy(record, hash[2:])
+	binary.BigEndian.PutUint32(record[breachSuffixSize:], uint32(count))
+	if _, err := iw.w.Write(record); err != nil {
+		return err
+	}
+
+	iw.buckets[int(hash[0])<<8|int(hash[1])]++
+	iw.records++
+	return nil
+}
+
+// finish writes the header with the fanout table.
+func (iw *breachIndexWriter) finish() error {
+	if iw.records == 0 {
+		return errors.New("no hashes found")
+	}
+	if err := iw.w.Flush(); err != nil {
+		return err
+	}
+
+	header := make([]byte, breachHeaderSize)
+	copy(header, breachIndexMagic)
+	var total uint64
+	for i, n := range iw.buckets {
+		total += n
+		binary.BigEndian.PutUint64(header[len(breachIndexMagic)+(i+1)*8:], total)
+	}
+
+	_, err := iw.f.WriteAt(header, 0)
+	return err
+}
+
+// readBreachHashFile passes every hash and count of a hash file to fn, in the order of the file.
+func readBreachHashFile(path string, fn func(hash []byte, count int) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return readBreachLines(f, sha1.Size*2, "", fn)
+}
+
+// readBreachRangeDir passes every hash and count of the range files in dir to fn, ordered by prefix.
+func readBreachRangeDir(dir string, fn func(hash []byte, count int) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	// Range files are named after their prefix. Entries are sorted by name, which orders the
+	// prefixes as long as their case is consistent; the index writer rejects any other order.
+	for _, entry := range entries {
+		prefix, ok := strings.CutSuffix(entry.Name(), ".txt")
+		if !ok || entry.IsDir() || len(prefix) != breachPrefixDigits {
+			continue
+		}
+		if strings.Trim(prefix, "0123456789ABCDEFabcdef") != "" {
+			continue
+		}
+
+		f, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		err = readBreachLines(f, sha1.Size*2-breachPrefixDigits, strings.ToUpper(prefix), fn)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("range file %s: %v", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// readBreachLines passes the hashes and counts of the "HEX:COUNT" lines of r to fn. The hashes are
+// prefixed with prefix. Lines with a count of 0 are padding added by the range API and are skipped.
+func readBreachLines(r io.Reader, hexDigits int, prefix string, fn func(hash []byte, count int) error) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if scanner.Text() == "" {
+			continue
+		}
+		hexHash, count, err := parseBreachLine(scanner.Text(), hexDigits)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if count == 0 {
+			continue
+		}
+
+		hash, err := hex.DecodeString(prefix + hexHash)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if err := fn(hash, count); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,238 @@
+package gofee
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// breachedPasswords are the passwords of the test datasets and how often they were breached.
+var breachedPasswords = map[string]int{
+	"password": 9659365,
+	"123456":   37359195,
+	"qwerty":   10556095,
+	"letmein":  1016700,
+	"dragon":   1000,
+}
+
+// sha1Hex returns the uppercase hex SHA-1 hash of the password.
+func sha1Hex(password string) string {
+	hash := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(hash[:]))
+}
+
+// writeHashFile writes the breached passwords as a hash file ordered by hash and returns its path.
+func writeHashFile(t *testing.T, lineEnding string) string {
+	t.Helper()
+
+	var lines []string
+	for password, count := range breachedPasswords {
+		lines = append(lines, fmt.Sprintf("%s:%d", sha1Hex(password), count))
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, lineEnding)+lineEnding), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeRangeDir writes the breached passwords as range files with padding lines and returns the directory.
+func writeRangeDir(t *testing.T) string {
+	t.Helper()
+
+	ranges := make(map[string][]string)
+	for password, count := range breachedPasswords {
+		hash := sha1Hex(password)
+		ranges[hash[:5]] = append(ranges[hash[:5]], fmt.Sprintf("%s:%d", hash[5:], count))
+	}
+
+	dir := t.TempDir()
+	for prefix, lines := range ranges {
+		sort.Strings(lines)
+		// The range API pads the responses with random suffixes of count 0.
+		lines = append(lines, strings.Repeat("F", 35)+":0")
+		if err := os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(strings.Join(lines, "\r\n")), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// TestLocalBreachDB looks up breached and unbreached passwords in all dataset formats.
+func TestLocalBreachDB(t *testing.T) {
+	index := filepath.Join(t.TempDir(), "pwned.idx")
+	if n, err := BuildBreachIndex(writeHashFile(t, "\n"), index); err != nil || n != uint64(len(breachedPasswords)) {
+		t.Fatalf("BuildBreachIndex() = %d, %v, want %d hashes", n, err, len(breachedPasswords))
+	}
+	rangeIndex := filepath.Join(t.TempDir(), "pwned.idx")
+	if _, err := BuildBreachIndex(writeRangeDir(t), rangeIndex); err != nil {
+		t.Fatalf("BuildBreachIndex() of range files error = %v", err)
+	}
+
+	datasets := []struct {
+		name string
+		path string
+	}{
+		{"Hash file", writeHashFile(t, "\n")},
+		{"Hash file with CRLF", writeHashFile(t, "\r\n")},
+		{"Index", index},
+		{"Index of range files", rangeIndex},
+	}
+
+	for _, dataset := range datasets {
+		t.Run(dataset.name, func(t *testing.T) {
+			db, err := OpenBreachDB(dataset.path)
+			if err != nil {
+				t.Fatalf("OpenBreachDB() error = %v", err)
+			}
+			defer db.Close()
+
+			for password, want := range breachedPasswords {
+				if got, err := db.Breaches(password); err != nil || got != want {
+					t.Errorf("Breaches(%q) = %d, %v, want %d", password, got, err, want)
+				}
+			}
+
+			// Passwords hashing before, between and after the breached ones are not found.
+			for _, password := range []string{"correct horse battery staple", "a", "", "zzzzzzzz"} {
+				if got, err := db.Breaches(password); err != nil || got != 0 {
+					t.Errorf("Breaches(%q) = %d, %v, want 0", password, got, err)
+				}
+			}
+		})
+	}
+}
+
+// TestLocalBreachDBRangeDir checks the lookup in a directory of range files.
+func TestLocalBreachDBRangeDir(t *testing.T) {
+	db, err := OpenBreachDB(writeRangeDir(t))
+	if err != nil {
+		t.Fatalf("OpenBreachDB() error = %v", err)
+	}
+	defer db.Close()
+
+	for password, want := range breachedPasswords {
+		if got, err := db.Breaches(password); err != nil || got != want {
+			t.Errorf("Breaches(%q) = %d, %v, want %d", password, got, err, want)
+		}
+	}
+
+	// A missing range file means the dataset is incomplete, which is an error.
+	if _, err := db.Breaches("correct horse battery staple"); err == nil {
+		t.Errorf("Breaches() without range file returned no error")
+	}
+}
+
+// TestBuildBreachIndexErrors checks that invalid datasets are rejected without writing an index.
+func TestBuildBreachIndexErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "Unordered", content: sha1Hex("b") + ":1\n" + sha1Hex("a") + ":1\n"},
+		{name: "Duplicate", content: sha1Hex("a") + ":1\n" + sha1Hex("a") + ":2\n"},
+		{name: "Invalid hash", content: strings.Repeat("X", 40) + ":1\n"},
+		{name: "Invalid count", content: sha1Hex("a") + ":many\n"},
+		{name: "Empty", content: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			source := filepath.Join(dir, "hashes.txt")
+			if err := os.WriteFile(source, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			dest := filepath.Join(dir, "pwned.idx")
+			if _, err := BuildBreachIndex(source, dest); err == nil {
+				t.Errorf("BuildBreachIndex() returned no error")
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+				t.Errorf("BuildBreachIndex() left %d files, want only the source", len(entries)-1)
+			}
+		})
+	}
+}
+
+// TestOpenBreachDBCorruptIndex checks that truncated indexes are rejected.
+func TestOpenBreachDBCorruptIndex(t *testing.T) {
+	index := filepath.Join(t.TempDir(), "pwned.idx")
+	if _, err := BuildBreachIndex(writeHashFile(t, "\n"), index); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(index, info.Size()-1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenBreachDB(index); err == nil {
+		t.Errorf("OpenBreachDB() of a truncated index returned no error")
+	}
+}
+
+// fakeBreachDB reports the passwords of a set as breached and remembers every lookup.
+type fakeBreachDB struct {
+	breached map[string]bool
+	lookups  []string
+}
+
+func (db *fakeBreachDB) Breaches(password string) (int, error) {
+	db.lookups = append(db.lookups, password)
+	if db.breached[password] {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// TestGenerateBreachDB checks that breached passwords are generated again.
+func TestGenerateBreachDB(t *testing.T) {
+	config := PasswordConfig{Type: "pin"}
+
+	// Collect the PINs the deterministic reader produces first.
+	first, err := NewGenerator(&drbg{seed: []byte("breach")}).Generate(2, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := &fakeBreachDB{breached: map[string]bool{first: true}}
+	config.BreachDB = db
+	result, err := NewGenerator(&drbg{seed: []byte("breach")}).GenerateResult(2, config)
+	if err != nil {
+		t.Fatalf("GenerateResult() error = %v", err)
+	}
+
+	if result.Password == first {
+		t.Errorf("GenerateResult() = %q, which is breached", result.Password)
+	}
+	if len(db.lookups) != 2 || db.lookups[0] != first {
+		t.Errorf("lookups = %q, want %q followed by the next PIN", db.lookups, first)
+	}
+	if result.Charset != Digits || result.Entropy != 2*math.Log2(10) {
+		t.Errorf("GenerateResult() = %+v, want the charset and entropy of the PIN", result)
+	}
+}
+
+// TestGenerateBreachDBExhausted checks that generation stops if every password is breached.
+func TestGenerateBreachDBExhausted(t *testing.T) {
+	breached := make(map[string]bool)
+	for _, c := range Digits {
+		breached[string(c)] = true
+	}
+
+	config := PasswordConfig{Type: "pin", BreachDB: &fakeBreachDB{breached: breached}}
+	if _, err := Generate(1, config); err == nil {
+		t.Errorf("Generate() returned no error although every PIN is breached")
+	}
+}
@@ -1,6 +1,13 @@
 package gofee
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
 
 // Charset constants for lowercase letters, uppercase letters, digits, and symbols.
 const (
@@ -11,44 +18,235 @@ const (
 	All     = Lowers + Uppers + Digits + Symbols
 )
 
+// Ambiguous contains characters that are easily confused with each other when
+// read aloud or printed, such as 0/O/o, 1/l/I/|, 5/S, 2/Z and 8/B.
+const Ambiguous = "0Oo1lI|5S2Z8B"
+
 type PasswordConfig struct {
 	IncludeLowers  bool
 	IncludeUppers  bool
 	IncludeDigits  bool
 	IncludeSymbols bool
 	Type           string
+	Passphrase     PassphraseConfig // Options for the "memorable" type.
+
+	// RequireAll requires at least one character of every included class.
+	RequireAll bool
+	// Minimum number of characters of each class, which must be included.
+	MinLowers  int
+	MinUppers  int
+	MinDigits  int
+	MinSymbols int
+
+	// CustomCharset replaces the included classes with an explicit alphabet, which may contain
+	// any Unicode characters, such as accented letters, Cyrillic or emoji.
+	CustomCharset string
+	// CustomSymbols replaces the Symbols of the symbol class.
+	CustomSymbols string
+	// ExcludeChars are removed from the charset.
+	ExcludeChars string
+	// ExcludeAmbiguous removes the Ambiguous characters from the charset.
+	ExcludeAmbiguous bool
+
+	// Pattern, if set, defines the shape of the password position by position, such as
+	// "Cvccvc-99-ss" (see PatternGenerator). It replaces the length and the character classes;
+	// only CustomSymbols and the excluded characters apply.
+	Pattern string
+
+	// FirstChars, if set, restricts the first character of the password to these characters,
+	// for systems requiring passwords to start with a letter, for example.
+	FirstChars string
+
+	// BreachDB, if set, is searched for every generated password. Passwords found in it are
+	// rejected and generated again.
+	BreachDB BreachDB
 }
 
-func BuildCharset(config PasswordConfig) string {
+// charClass is a named class of characters, which can be included in the charset.
+type charClass struct {
+	name     string
+	chars    string
+	included bool
+	min      int
+}
+
+// alphabets are the characters of the classes, in the order they make up a charset, and the
+// ambiguous characters.
+type alphabets struct {
+	lowers, uppers, digits, symbols string
+	ambiguous                       string
+}
 
-	switch config.Type {
-	case "pin":
-		return Digits
-	case "memorable":
-		return Lowers + Uppers
+// defaultAlphabets are the alphabets of generated passwords.
+var defaultAlphabets = alphabets{lowers: Lowers, uppers: Uppers, digits: Digits, symbols: Symbols, ambiguous: Ambiguous}
+
+// charClasses returns the character classes of the config with their effective characters,
+// after applying the custom charset, the custom symbols and the excluded and ambiguous characters.
+// Classes without any characters left are never included.
+func (config PasswordConfig) charClasses() []charClass {
+	return config.charClassesOf(defaultAlphabets)
+}
+
+// charClassesOf returns the character classes like charClasses, based on the given alphabets.
+func (config PasswordConfig) charClassesOf(a alphabets) []charClass {
+	symbols := a.symbols
+	if config.CustomSymbols != "" {
+		symbols = dedupeChars(config.CustomSymbols)
 	}
 
-	if config.IncludeLowers && config.IncludeUppers && config.IncludeDigits && config.IncludeSymbols {
-		return All
+	classes := []charClass{
+		{"lowercase letters", a.lowers, config.IncludeLowers, config.MinLowers},
+		{"uppercase letters", a.uppers, config.IncludeUppers, config.MinUppers},
+		{"digits", a.digits, config.IncludeDigits, config.MinDigits},
+		{"symbols", symbols, config.IncludeSymbols, config.MinSymbols},
 	}
 
-	var builder strings.Builder
+	excluded := config.ExcludeChars
+	if config.ExcludeAmbiguous {
+		excluded += a.ambiguous
+	}
+
+	for i := range classes {
+		class := &classes[i]
+
+		switch {
+		case config.Type == "pin":
+			// A pin only ever consists of digits, regardless of the included classes.
+			class.included = class.name == "digits"
+		case config.CustomCharset != "":
+			// A custom charset includes every class it shares characters with.
+			class.chars = keepChars(class.chars, config.CustomCharset)
+			class.included = class.chars != ""
+		}
+
+		class.chars = removeChars(class.chars, excluded)
+		if class.chars == "" {
+			class.included = false
+		}
+	}
+
+	return classes
+}
+
+// excludedChars returns all characters that must not be part of the charset.
+func (config PasswordConfig) excludedChars() string {
+	if config.ExcludeAmbiguous {
+		return config.ExcludeChars + Ambiguous
+	}
+	return config.ExcludeChars
+}
+
+// normalize returns the config with its custom characters in Unicode normalization form C, so
+// characters typed as a letter and a combining accent, such as "e\u0301", match their
+// precomposed form "é".
+func (config PasswordConfig) normalize() PasswordConfig {
+	config.CustomCharset = norm.NFC.String(config.CustomCharset)
+	config.CustomSymbols = norm.NFC.String(config.CustomSymbols)
+	config.ExcludeChars = norm.NFC.String(config.ExcludeChars)
+	config.FirstChars = norm.NFC.String(config.FirstChars)
+	config.Pattern = norm.NFC.String(config.Pattern)
+	return config
+}
+
+// emojiComponents contains the runes which only form an emoji together with other runes: the
+// regional indicators of flags and the skin tone modifiers.
+var emojiComponents = &unicode.RangeTable{
+	R32: []unicode.Range32{
+		{Lo: 0x1f1e6, Hi: 0x1f1ff, Stride: 1}, // Regional indicators A to Z.
+		{Lo: 0x1f3fb, Hi: 0x1f3ff, Stride: 1}, // Emoji modifiers Fitzpatrick 1-2 to 6.
+	},
+}
 
-	if config.IncludeLowers {
-		builder.WriteString(Lowers)
+// validate checks the custom characters of the config, which must be normalized.
+func (config PasswordConfig) validate() error {
+	fields := []struct {
+		name  string
+		chars string
+	}{
+		{"charset", config.CustomCharset},
+		{"symbols", config.CustomSymbols},
+		{"excluded characters", config.ExcludeChars},
+		{"first characters", config.FirstChars},
 	}
 
-	if config.IncludeUppers {
-		builder.WriteString(Uppers)
+	for _, field := range fields {
+		if !utf8.ValidString(field.chars) {
+			return fmt.Errorf("invalid UTF-8 in %s", field.name)
+		}
+		for _, c := range field.chars {
+			// Passwords are generated rune by rune, so every rune must be a character of its own:
+			// control and format characters, combining marks, runes that compose with the
+			// preceding rune, such as Hangul vowel jamo, and the emoji modifiers and regional
+			// indicators that form skin tones and flags are not supported.
+			if !unicode.IsGraphic(c) || unicode.IsMark(c) || !norm.NFC.PropertiesString(string(c)).BoundaryBefore() || unicode.Is(emojiComponents, c) {
+				return fmt.Errorf("unsupported character %q in %s", c, field.name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// BuildCharset returns the characters a password of the given config is drawn from.
+// Every character appears only once, so the size of the charset matches its entropy.
+func BuildCharset(config PasswordConfig) string {
+	return buildCharset(config, defaultAlphabets)
+}
+
+// buildCharset returns the charset like BuildCharset, based on the given alphabets.
+func buildCharset(config PasswordConfig, a alphabets) string {
+	config = config.normalize()
+
+	// A custom charset is used as it is, apart from the excluded characters.
+	if config.CustomCharset != "" && config.Type != "pin" {
+		excluded := config.ExcludeChars
+		if config.ExcludeAmbiguous {
+			excluded += a.ambiguous
+		}
+		return removeChars(dedupeChars(config.CustomCharset), excluded)
 	}
 
-	if config.IncludeDigits {
-		builder.WriteString(Digits)
+	var builder strings.Builder
+
+	for _, class := range config.charClassesOf(a) {
+		if class.included {
+			builder.WriteString(class.chars)
+		}
 	}
 
-	if config.IncludeSymbols {
-		builder.WriteString(Symbols)
+	// Custom symbols may overlap with the other classes.
+	return dedupeChars(builder.String())
+}
+
+// dedupeChars removes repeated characters from s, keeping their first occurrence.
+func dedupeChars(s string) string {
+	var builder strings.Builder
+
+	for i, c := range s {
+		if !strings.ContainsRune(s[:i], c) {
+			builder.WriteRune(c)
+		}
 	}
 
 	return builder.String()
 }
+
+// removeChars returns the characters of s that are not part of chars.
+func removeChars(s, chars string) string {
+	return strings.Map(func(c rune) rune {
+		if strings.ContainsRune(chars, c) {
+			return -1
+		}
+		return c
+	}, s)
+}
+
+// keepChars returns the characters of s that are part of chars.
+func keepChars(s, chars string) string {
+	return strings.Map(func(c rune) rune {
+		if !strings.ContainsRune(chars, c) {
+			return -1
+		}
+		return c
+	}, s)
+}
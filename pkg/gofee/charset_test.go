@@ -0,0 +1,168 @@
+package gofee
+
+import "testing"
+
+// TestBuildCharset tests the charsets built from various configurations.
+func TestBuildCharset(t *testing.T) {
+	tests := []struct {
+		name   string
+		config PasswordConfig
+		want   string
+	}{
+		{
+			name:   "All classes",
+			config: PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true},
+			want:   All,
+		},
+		{
+			name:   "Pin ignores classes",
+			config: PasswordConfig{IncludeLowers: true, Type: "pin"},
+			want:   Digits,
+		},
+		{
+			name:   "Pin with excluded characters",
+			config: PasswordConfig{Type: "pin", ExcludeChars: "019"},
+			want:   "2345678",
+		},
+		{
+			name:   "Custom charset is deduplicated",
+			config: PasswordConfig{CustomCharset: "abcabcxyz"},
+			want:   "abcxyz",
+		},
+		{
+			name:   "Custom charset with excluded characters",
+			config: PasswordConfig{CustomCharset: "abc123", ExcludeChars: "b2"},
+			want:   "ac13",
+		},
+		{
+			name:   "Custom symbols",
+			config: PasswordConfig{IncludeDigits: true, IncludeSymbols: true, CustomSymbols: "!!-_"},
+			want:   Digits + "!-_",
+		},
+		{
+			name:   "Custom symbols overlapping other classes",
+			config: PasswordConfig{IncludeDigits: true, IncludeSymbols: true, CustomSymbols: "1!"},
+			want:   Digits + "!",
+		},
+		{
+			name:   "Excluded characters",
+			config: PasswordConfig{IncludeDigits: true, IncludeSymbols: true, ExcludeChars: `"'\<>!@#$%^&*()-_=+[]{}|;:,.?/~`},
+			want:   Digits,
+		},
+		{
+			name:   "Ambiguous characters",
+			config: PasswordConfig{IncludeDigits: true, IncludeUppers: true, ExcludeAmbiguous: true},
+			want:   "ACDEFGHJKLMNPQRTUVWXY" + "34679",
+		},
+		{
+			name:   "Ambiguous and excluded characters in custom charset",
+			config: PasswordConfig{CustomCharset: "abc0O1l", ExcludeChars: "a", ExcludeAmbiguous: true},
+			want:   "bc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildCharset(tt.config); got != tt.want {
+				t.Errorf("BuildCharset() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCharClasses checks that custom charsets only include the classes they share characters with.
+func TestCharClasses(t *testing.T) {
+	config := PasswordConfig{CustomCharset: "abc!?", ExcludeChars: "c"}
+
+	want := map[string]string{
+		"lowercase letters": "ab",
+		"uppercase letters": "",
+		"digits":            "",
+		"symbols":           "!?",
+	}
+
+	for _, class := range config.charClasses() {
+		if class.chars != want[class.name] || class.included != (want[class.name] != "") {
+			t.Errorf("class %s = %q (included %v), want %q", class.name, class.chars, class.included, want[class.name])
+		}
+	}
+}
+
+// TestPasswordConfigValidate tests the validation of custom characters.
+func TestPasswordConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  PasswordConfig
+		wantErr bool
+	}{
+		{
+			name:   "Printable ASCII",
+			config: PasswordConfig{CustomCharset: "abc !~", CustomSymbols: "!?", ExcludeChars: `"'\`},
+		},
+		{
+			name:    "Control character in charset",
+			config:  PasswordConfig{CustomCharset: "abc\t"},
+			wantErr: true,
+		},
+		{
+			name:   "Unicode characters",
+			config: PasswordConfig{CustomCharset: "äöüßжя€😀", CustomSymbols: "§", FirstChars: "ä"},
+		},
+		{
+			name:    "Combining mark",
+			config:  PasswordConfig{CustomCharset: "abc\u0301"},
+			wantErr: true,
+		},
+		{
+			name:    "Variation selector",
+			config:  PasswordConfig{CustomSymbols: "❤\ufe0f"},
+			wantErr: true,
+		},
+		{
+			name:    "Zero width joiner",
+			config:  PasswordConfig{CustomCharset: "ab\u200d"},
+			wantErr: true,
+		},
+		{
+			name:    "Regional indicators",
+			config:  PasswordConfig{CustomCharset: "ab\U0001f1e9\U0001f1ea"},
+			wantErr: true,
+		},
+		{
+			name:    "Emoji modifier",
+			config:  PasswordConfig{CustomSymbols: "\U0001f44b\U0001f3fd"},
+			wantErr: true,
+		},
+		{
+			name:    "Hangul vowel jamo",
+			config:  PasswordConfig{CustomCharset: "\u1100\u1161"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid UTF-8",
+			config:  PasswordConfig{ExcludeChars: "a\xff"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestPasswordConfigNormalize checks that decomposed characters are composed, so they are
+// a single character of the charset.
+func TestPasswordConfigNormalize(t *testing.T) {
+	config := PasswordConfig{CustomCharset: "e\u0301a\u0308", ExcludeChars: "a\u0308"}
+
+	if got := BuildCharset(config); got != "é" {
+		t.Errorf("BuildCharset() = %q, want %q", got, "é")
+	}
+	if err := config.normalize().validate(); err != nil {
+		t.Errorf("validate() error = %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package gofee
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+// classConstraint requires a password to contain at least min characters of a character class.
+type classConstraint struct {
+	name  string
+	chars string
+	min   int
+}
+
+// constraints returns the per-class minimum counts requested by the config.
+// It returns nil if the config does not constrain the password, or an error if a
+// minimum is negative or requires a class that is not included in the charset.
+func (config PasswordConfig) constraints() ([]classConstraint, error) {
+	return config.constraintsOf(defaultAlphabets)
+}
+
+// constraintsOf returns the constraints like constraints, based on the given alphabets.
+func (config PasswordConfig) constraintsOf(a alphabets) ([]classConstraint, error) {
+	var constraints []classConstraint
+	for _, class := range config.charClassesOf(a) {
+		min := class.min
+		if min < 0 {
+			return nil, fmt.Errorf("minimum number of %s must not be negative", class.name)
+		}
+
+		// Require at least one character of every included class.
+		if config.RequireAll && class.included && min == 0 {
+			min = 1
+		}
+
+		if min == 0 {
+			continue
+		}
+		if !class.included {
+			return nil, fmt.Errorf("%s are required but not included", class.name)
+		}
+
+		constraints = append(constraints, classConstraint{name: class.name, chars: class.chars, min: min})
+	}
+
+	return constraints, nil
+}
+
+// ConstrainedGenerator generates passwords drawn from a charset, which contain
+// at least a minimum number of characters of some character classes.
+type ConstrainedGenerator struct {
+	g           *Generator
+	length      int
+	charset     string
+	constraints []classConstraint
+	entropy     float64
+}
+
+// newConstrainedGenerator returns a ConstrainedGenerator or an error if the
+// minimum counts do not fit into the password.
+func (g *Generator) newConstrainedGenerator(length int, charset string, constraints []classConstraint) (*ConstrainedGenerator, error) {
+	if required := requiredCount(constraints); required > length {
+		return nil, fmt.Errorf("invalid constraints: length %d is too short for %d required characters", length, required)
+	}
+
+	entropy, err := constrainedEntropy(length, utf8.RuneCountInString(charset), constraints)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating entropy: %v", err)
+	}
+
+	return &ConstrainedGenerator{g: g, length: length, charset: charset, constraints: constraints, entropy: entropy}, nil
+}
+
+// Generate returns a new random password satisfying the constraints.
+func (c *ConstrainedGenerator) Generate() (string, error) {
+	password, err := c.g.mapToConstraints(c.length, c.charset, c.constraints)
+	if err != nil {
+		return "", fmt.Errorf("error mapping number to charset: %v", err)
+	}
+	return password, nil
+}
+
+// Entropy returns the conservative entropy estimate of constrainedEntropy.
+func (c *ConstrainedGenerator) Entropy() float64 {
+	return c.entropy
+}
+
+// Charset returns the characters the passwords are drawn from.
+func (c *ConstrainedGenerator) Charset() string {
+	return c.charset
+}
+
+// mapToConstraints generates a random password of the given length using the characters of charset,
+// which contains at least the minimum number of characters of every constrained class.
+// The required characters are drawn from their class, the remaining ones from the whole charset,
+// and the result is shuffled, so the required characters can end up at any position.
+func (g *Generator) mapToConstraints(length int, charset string, constraints []classConstraint) (string, error) {
+	// Return an error if the length is invalid.
+	if length <= 0 {
+		return "", fmt.Errorf("length must be greater than 0")
+	}
+
+	// Return an error if no characters are available in the charset.
+	if len(charset) == 0 {
+		return "", fmt.Errorf("charset is empty")
+	}
+
+	// Return an error if the minimum counts do not fit into the password.
+	if required := requiredCount(constraints); required > length {
+		return "", fmt.Errorf("length %d is too short for %d required characters", length, required)
+	}
+
+	ret := make([]rune, 0, length)
+
+	// Draw the required characters from their classes.
+	for _, c := range constraints {
+		part, err := g.mapToCharset(c.min, c.chars)
+		if err != nil {
+			return "", err
+		}
+		ret = append(ret, []rune(part)...)
+	}
+
+	// Fill the remaining positions from the whole charset.
+	if rest := length - len(ret); rest > 0 {
+		part, err := g.mapToCharset(rest, charset)
+		if err != nil {
+			return "", err
+		}
+		ret = append(ret, []rune(part)...)
+	}
+
+	// Shuffle the password with an unbiased Fisher-Yates shuffle to remove positional bias.
+	for i := len(ret) - 1; i > 0; i-- {
+		j, err := g.randomIndex(i + 1)
+		if err != nil {
+			return "", err
+		}
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+
+	return string(ret), nil
+}
+
+// constrainedEntropy returns the entropy (in bits) of a password generated by mapToConstraints.
+// Every required character contributes the entropy of its class and every other character the
+// entropy of the whole charset. The entropy added by the shuffle is not counted, so the result
+// is a conservative lower bound.
+func constrainedEntropy(length, charsetSize int, constraints []classConstraint) (float64, error) {
+	required := requiredCount(constraints)
+	if required > length {
+		return 0, fmt.Errorf("length %d is too short for %d required characters", length, required)
+	}
+
+	var entropy float64
+	for _, c := range constraints {
+		entropy += float64(c.min) * math.Log2(float64(utf8.RuneCountInString(c.chars)))
+	}
+
+	// Without any free positions, the charset does not add entropy.
+	if rest := length - required; rest > 0 {
+		free, err := CalculateEntropy(charsetSize, rest)
+		if err != nil {
+			return 0, err
+		}
+		entropy += free
+	}
+
+	return entropy, nil
+}
+
+// requiredCount returns the total number of characters required by the constraints.
+func requiredCount(constraints []classConstraint) int {
+	var required int
+	for _, c := range constraints {
+		required += c.min
+	}
+	return required
+}
@@ -0,0 +1,171 @@
+package gofee
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestGenerateConstraints checks that generated passwords contain the minimum number of characters per class.
+func TestGenerateConstraints(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		config  PasswordConfig
+		wantErr bool
+	}{
+		{
+			name:   "Require all classes",
+			length: 4,
+			config: PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true, RequireAll: true},
+		},
+		{
+			name:   "Minimum digits and symbols",
+			length: 10,
+			config: PasswordConfig{IncludeLowers: true, IncludeDigits: true, IncludeSymbols: true, MinDigits: 3, MinSymbols: 2},
+		},
+		{
+			name:   "Require all with minimum",
+			length: 6,
+			config: PasswordConfig{IncludeLowers: true, IncludeUppers: true, RequireAll: true, MinUppers: 5},
+		},
+		{
+			name:    "Minimums exceed length",
+			length:  4,
+			config:  PasswordConfig{IncludeLowers: true, IncludeDigits: true, MinDigits: 3, MinLowers: 2},
+			wantErr: true,
+		},
+		{
+			name:    "Minimum for excluded class",
+			length:  8,
+			config:  PasswordConfig{IncludeLowers: true, MinSymbols: 1},
+			wantErr: true,
+		},
+		{
+			name:    "Negative minimum",
+			length:  8,
+			config:  PasswordConfig{IncludeLowers: true, MinLowers: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				pw, err := Generate(tt.length, tt.config)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr {
+					return
+				}
+
+				if len(pw) != tt.length {
+					t.Fatalf("Generate() length = %d, want %d", len(pw), tt.length)
+				}
+
+				constraints, _ := tt.config.constraints()
+				for _, c := range constraints {
+					if got := countIn(pw, c.chars); got < c.min {
+						t.Fatalf("Generate() = %q contains %d %s, want at least %d", pw, got, c.name, c.min)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestGenerateConstraintsPositions checks that required characters are not biased towards any position.
+func TestGenerateConstraintsPositions(t *testing.T) {
+	length := 8
+	numPws := 40000
+	config := PasswordConfig{IncludeLowers: true, IncludeSymbols: true, MinSymbols: 1}
+
+	// Count the symbols found at every position.
+	positions := make([]int, length)
+	var total int
+	for i := 0; i < numPws; i++ {
+		pw, err := Generate(length, config)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		for p, c := range pw {
+			if Contains(Symbols, c) {
+				positions[p]++
+				total++
+			}
+		}
+	}
+
+	// Every position should hold the same share of symbols, with a 10% tolerance.
+	expected := total / length
+	tolerance := expected / 10
+	for p, count := range positions {
+		if count < expected-tolerance || count > expected+tolerance {
+			t.Errorf("Position %d holds %d symbols, expected around %d", p, count, expected)
+		}
+	}
+}
+
+// TestConstrainedEntropy checks the entropy of constrained passwords.
+func TestConstrainedEntropy(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		config  PasswordConfig
+		want    float64
+		wantErr bool
+	}{
+		{
+			name:   "Require all classes",
+			length: 16,
+			config: PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true, RequireAll: true},
+			want:   2*math.Log2(26) + math.Log2(10) + math.Log2(28) + 12*math.Log2(90),
+		},
+		{
+			name:   "Only required characters",
+			length: 3,
+			config: PasswordConfig{IncludeLowers: true, IncludeDigits: true, MinDigits: 3},
+			want:   3 * math.Log2(10),
+		},
+		{
+			name:    "Minimums exceed length",
+			length:  2,
+			config:  PasswordConfig{IncludeLowers: true, IncludeDigits: true, MinDigits: 3},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraints, err := tt.config.constraints()
+			if err != nil {
+				t.Fatalf("constraints() error = %v", err)
+			}
+
+			got, err := constrainedEntropy(tt.length, len(BuildCharset(tt.config)), constraints)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("constrainedEntropy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("constrainedEntropy() = %v, want %v", got, tt.want)
+			}
+
+			// The constrained entropy never exceeds the entropy of the unconstrained password.
+			if unconstrained, _ := CalculateEntropy(len(BuildCharset(tt.config)), tt.length); got > unconstrained {
+				t.Errorf("constrainedEntropy() = %v exceeds unconstrained entropy %v", got, unconstrained)
+			}
+		})
+	}
+}
+
+// countIn returns the number of characters of s that are part of set.
+func countIn(s, set string) int {
+	var n int
+	for _, c := range s {
+		if strings.ContainsRune(set, c) {
+			n++
+		}
+	}
+	return n
+}
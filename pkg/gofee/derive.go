@@ -0,0 +1,185 @@
+package gofee
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DeriveVersion is the latest version of the derivation algorithm, which is used by default.
+const DeriveVersion = 1
+
+// maxDeriveAttempts is the maximum number of passwords drawn until one satisfies the constraints.
+const maxDeriveAttempts = 1000
+
+// deriveScheme holds the parameters of a version of the derivation algorithm.
+type deriveScheme struct {
+	time      uint32    // Argon2id passes.
+	memory    uint32    // Argon2id memory in KiB.
+	threads   uint8     // Argon2id lanes.
+	alphabets alphabets // The characters of the classes, independent of the constants.
+}
+
+// deriveSchemes are the supported versions of the derivation algorithm. A version never changes
+// once released, so derived passwords stay the same across releases. The alphabets are spelled
+// out, as changing the Symbols or Ambiguous constants must not change derived passwords.
+var deriveSchemes = map[int]deriveScheme{
+	1: {
+		time:    3,
+		memory:  64 * 1024,
+		threads: 4,
+		alphabets: alphabets{
+			lowers:    "abcdefghijklmnopqrstuvwxyz",
+			uppers:    "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+			digits:    "0123456789",
+			symbols:   "!@#$%^&*()-_=+[]{}|;:,.<>?/~",
+			ambiguous: "0Oo1lI|5S2Z8B",
+		},
+	},
+}
+
+// DeriveParams identifies a derived password.
+type DeriveParams struct {
+	Site    string // The site the password is used for, such as example.com.
+	User    string // The user name on the site, which may be empty.
+	Counter uint32 // Incremented to change the password of a site.
+	Version int    // The version of the algorithm, DeriveVersion if 0.
+}
+
+// Derive returns the password for a site derived from a master password, without storing
+// anything: the same inputs always give the same password. Only passwords drawn from a charset
+// are supported.
+//
+// Version 1 of the algorithm works as follows:
+//
+//  1. The master password and the user are normalized to NFC, the site is also trimmed and
+//     lowercased.
+//  2. The salt is "gofee-derive-v1", followed by the site, the user and the counter. The site
+//     and the user are prefixed with their length in bytes as a big endian uint32, the counter
+//     is a big endian uint32.
+//  3. A 32 byte key is derived with Argon2id with 3 passes over 64 MiB using 4 lanes.
+//  4. The key seeds a stream of bytes, whose blocks are HMAC-SHA256(key, i) for the block
+//     number i, a big endian uint64 starting at 0.
+//  5. Every character is drawn from the runes of the charset: the lowercase letters, uppercase
+//     letters, digits and symbols of the included classes in this order, as pinned in
+//     deriveSchemes, or the custom charset, with the excluded and ambiguous characters removed
+//     and repeated characters kept at their first occurrence. The smallest number of
+//     bytes holding n-1 for a charset of n runes is read from the stream as a big endian
+//     number, the bits above the bit length of n-1 are cleared, and the number is drawn again
+//     if it is n or greater.
+//  6. If the password lacks a character required by the minimum counts or RequireAll, the
+//     next password is drawn from the stream.
+func Derive(master string, length int, config PasswordConfig, params DeriveParams) (Result, error) {
+	version := params.Version
+	if version == 0 {
+		version = DeriveVersion
+	}
+	scheme, ok := deriveSchemes[version]
+	if !ok {
+		return Result{}, fmt.Errorf("unsupported derivation version %d", version)
+	}
+
+	switch {
+	case master == "":
+		return Result{}, fmt.Errorf("master password must not be empty")
+	case strings.TrimSpace(params.Site) == "":
+		return Result{}, fmt.Errorf("site must not be empty")
+	case length <= 0:
+		return Result{}, fmt.Errorf("length must be greater than 0")
+	case (config.Type != "" && config.Type != "pin") || config.Pattern != "" || config.FirstChars != "":
+		return Result{}, fmt.Errorf("only passwords drawn from a charset can be derived")
+	}
+
+	config = config.normalize()
+	if err := config.validate(); err != nil {
+		return Result{}, fmt.Errorf("invalid charset: %v", err)
+	}
+	charset := buildCharset(config, scheme.alphabets)
+	if charset == "" {
+		return Result{}, fmt.Errorf("charset is empty")
+	}
+	constraints, err := config.constraintsOf(scheme.alphabets)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid constraints: %v", err)
+	}
+	// Rejecting passwords lacking required characters shrinks the space like mapToConstraints does.
+	entropy, err := constrainedEntropy(length, utf8.RuneCountInString(charset), constraints)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid constraints: %v", err)
+	}
+
+	key := deriveKey(master, params, version, scheme)
+	g := NewGenerator(&deriveStream{key: key})
+
+	for i := 0; i < maxDeriveAttempts; i++ {
+		password, err := g.mapToCharset(length, charset)
+		if err != nil {
+			return Result{}, err
+		}
+		if satisfies(password, constraints) {
+			return Result{Password: password, Charset: charset, Entropy: entropy}, nil
+		}
+	}
+	return Result{}, fmt.Errorf("no password satisfying the constraints after %d attempts", maxDeriveAttempts)
+}
+
+// deriveKey derives the key of a site from the master password with Argon2id.
+func deriveKey(master string, params DeriveParams, version int, scheme deriveScheme) []byte {
+	site := norm.NFC.String(strings.ToLower(strings.TrimSpace(params.Site)))
+	user := norm.NFC.String(params.User)
+
+	salt := []byte(fmt.Sprintf("gofee-derive-v%d", version))
+	salt = binary.BigEndian.AppendUint32(salt, uint32(len(site)))
+	salt = append(salt, site...)
+	salt = binary.BigEndian.AppendUint32(salt, uint32(len(user)))
+	salt = append(salt, user...)
+	salt = binary.BigEndian.AppendUint32(salt, params.Counter)
+
+	return argon2.IDKey([]byte(norm.NFC.String(master)), salt, scheme.time, scheme.memory, scheme.threads, 32)
+}
+
+// satisfies reports whether the password contains the minimum number of characters of every class.
+func satisfies(password string, constraints []classConstraint) bool {
+	for _, c := range constraints {
+		count := 0
+		for _, r := range password {
+			if strings.ContainsRune(c.chars, r) {
+				count++
+			}
+		}
+		if count < c.min {
+			return false
+		}
+	}
+	return true
+}
+
+// deriveStream is the deterministic stream of bytes seeded by a derived key. Its blocks are
+// HMAC-SHA256(key, i) for the block number i.
+type deriveStream struct {
+	key   []byte
+	block uint64
+	buf   []byte
+}
+
+// Read fills p with the next bytes of the stream. It never fails.
+func (s *deriveStream) Read(p []byte) (int, error) {
+	for n := 0; n < len(p); {
+		if len(s.buf) == 0 {
+			mac := hmac.New(sha256.New, s.key)
+			mac.Write(binary.BigEndian.AppendUint64(nil, s.block))
+			s.buf = mac.Sum(nil)
+			s.block++
+		}
+		c := copy(p[n:], s.buf)
+		s.buf = s.buf[c:]
+		n += c
+	}
+	return len(p), nil
+}
@@ -0,0 +1,180 @@
+package gofee
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// deriveAll includes all character classes.
+var deriveAll = PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true}
+
+// TestDeriveVectors checks the test vectors of version 1 of the derivation algorithm. The
+// passwords must never change, or users lose access to the sites they derived passwords for.
+func TestDeriveVectors(t *testing.T) {
+	tests := []struct {
+		name   string
+		master string
+		length int
+		config PasswordConfig
+		params DeriveParams
+		want   string
+	}{
+		{"All classes", "correct horse battery staple", 16, deriveAll, DeriveParams{Site: "example.com", User: "alice", Counter: 1}, "Y+5jB7$#aI6jnki)"},
+		{"Counter", "correct horse battery staple", 16, deriveAll, DeriveParams{Site: "example.com", User: "alice", Counter: 2}, "_kToF=y/c%V~aanu"},
+		{"Site", "correct horse battery staple", 16, deriveAll, DeriveParams{Site: "example.org", User: "alice", Counter: 1}, "^koN3FH:kE:<8Rc~"},
+		{"User", "correct horse battery staple", 16, deriveAll, DeriveParams{Site: "example.com", User: "bob", Counter: 1}, "6&:#]Dsxr[21:mWu"},
+		{"No user", "correct horse battery staple", 16, deriveAll, DeriveParams{Site: "example.com", Counter: 1}, "@8!TLAl{{]6RDc#A"},
+		{"Require all", "Tr0ub4dor&3", 24, PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, RequireAll: true}, DeriveParams{Site: "example.com", User: "alice", Counter: 1}, "eC0cwVOq4vSO3zLjlg21XfMQ"},
+		{"No ambiguous characters", "correct horse battery staple", 16, PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true, ExcludeAmbiguous: true}, DeriveParams{Site: "example.com", User: "alice", Counter: 1}, "!/*jE)][aM(jpki."},
+		{"Custom symbols", "correct horse battery staple", 16, PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true, CustomSymbols: "!#%+=?"}, DeriveParams{Site: "example.com", User: "alice", Counter: 1}, "Y5jB7+%aI6jnkiAG"},
+		{"Excluded characters", "correct horse battery staple", 16, PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true, ExcludeChars: "aeiouAEIOU$#"}, DeriveParams{Site: "example.com", User: "alice", Counter: 1}, "8?*mJ)][bR(mrnl,"},
+		{"PIN", "Tr0ub4dor&3", 6, PasswordConfig{Type: "pin"}, DeriveParams{Site: "bank.example", User: "alice", Counter: 1}, "778321"},
+		{"Unicode", "mot de passe maître", 12, PasswordConfig{CustomCharset: "äöüß"}, DeriveParams{Site: "example.de", User: "jürgen", Counter: 1}, "üüßüüßöüöößß"},
+		// Version 1 is the default.
+		{"Explicit version", "correct horse battery staple", 16, deriveAll, DeriveParams{Site: "example.com", User: "alice", Counter: 1, Version: 1}, "Y+5jB7$#aI6jnki)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Derive(tt.master, tt.length, tt.config, tt.params)
+			if err != nil {
+				t.Fatalf("Derive() error = %v", err)
+			}
+			if result.Password != tt.want {
+				t.Errorf("Derive() = %q, want %q", result.Password, tt.want)
+			}
+		})
+	}
+}
+
+// TestDerivePinnedAlphabets checks that derived passwords do not depend on the alphabets of
+// generated passwords.
+func TestDerivePinnedAlphabets(t *testing.T) {
+	defer func(a alphabets) { defaultAlphabets = a }(defaultAlphabets)
+	defaultAlphabets = alphabets{lowers: Uppers, uppers: Lowers, digits: Digits, symbols: "!?", ambiguous: "abc"}
+
+	config := deriveAll
+	config.ExcludeAmbiguous = true
+	result, err := Derive("correct horse battery staple", 16, config, DeriveParams{Site: "example.com", User: "alice", Counter: 1})
+	if err != nil {
+		t.Fatalf("Derive() error = %v", err)
+	}
+	if want := "!/*jE)][aM(jpki."; result.Password != want {
+		t.Errorf("Derive() = %q, want %q", result.Password, want)
+	}
+}
+
+// TestDeriveIntermediates checks the key and the first bytes of the stream of a test vector, so
+// other implementations of the algorithm can be checked step by step.
+func TestDeriveIntermediates(t *testing.T) {
+	key := deriveKey("correct horse battery staple", DeriveParams{Site: "example.com", User: "alice", Counter: 1}, 1, deriveSchemes[1])
+	if got, want := hex.EncodeToString(key), "ed374ed571a51fe0c933f39e6b575d4d31931fb95a3db6b65c1da5b0c38fde0a"; got != want {
+		t.Errorf("deriveKey() = %s, want %s", got, want)
+	}
+
+	// Read across the first block boundary in uneven steps.
+	stream := &deriveStream{key: key}
+	var got []byte
+	for _, n := range []int{1, 30, 9} {
+		p := make([]byte, n)
+		if _, err := stream.Read(p); err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		got = append(got, p...)
+	}
+	if want := "b2cbdc7877b96178091bbbc1c05fff80223af409e78d778adb0847d81aa00076151d143669ae35ed"; hex.EncodeToString(got) != want {
+		t.Errorf("stream = %x, want %s", got, want)
+	}
+}
+
+// TestDeriveNormalization checks that equivalent inputs derive the same password.
+func TestDeriveNormalization(t *testing.T) {
+	params := DeriveParams{Site: "example.de", User: "jürgen", Counter: 1}
+	want, err := Derive("maître", 16, deriveAll, params)
+	if err != nil {
+		t.Fatalf("Derive() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		master string
+		params DeriveParams
+	}{
+		// The decomposed forms of î and ü.
+		{"Decomposed master password", "mai\u0302tre", params},
+		{"Decomposed user", "maître", DeriveParams{Site: "example.de", User: "ju\u0308rgen", Counter: 1}},
+		{"Site case and spaces", "maître", DeriveParams{Site: " Example.DE ", User: "jürgen", Counter: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Derive(tt.master, 16, deriveAll, tt.params)
+			if err != nil {
+				t.Fatalf("Derive() error = %v", err)
+			}
+			if got.Password != want.Password {
+				t.Errorf("Derive() = %q, want %q", got.Password, want.Password)
+			}
+		})
+	}
+}
+
+// TestDeriveResult checks the charset and the entropy of a derived password.
+func TestDeriveResult(t *testing.T) {
+	result, err := Derive("correct horse battery staple", 6, PasswordConfig{Type: "pin"}, DeriveParams{Site: "example.com"})
+	if err != nil {
+		t.Fatalf("Derive() error = %v", err)
+	}
+	if result.Charset != Digits {
+		t.Errorf("Charset = %q, want %q", result.Charset, Digits)
+	}
+	if want := 19.93; result.Entropy < want || result.Entropy > want+0.01 {
+		t.Errorf("Entropy = %.2f, want %.2f", result.Entropy, want)
+	}
+
+	// Required characters are counted with the entropy of their class, like Generate does.
+	config := deriveAll
+	config.RequireAll = true
+	result, err = Derive("correct horse battery staple", 16, config, DeriveParams{Site: "example.com"})
+	if err != nil {
+		t.Fatalf("Derive() error = %v", err)
+	}
+	if want := 95.43; result.Entropy < want || result.Entropy > want+0.01 {
+		t.Errorf("Entropy with RequireAll = %.2f, want %.2f", result.Entropy, want)
+	}
+}
+
+// TestDeriveErrors checks that unsupported inputs are rejected.
+func TestDeriveErrors(t *testing.T) {
+	site := DeriveParams{Site: "example.com"}
+
+	tests := []struct {
+		name    string
+		master  string
+		length  int
+		config  PasswordConfig
+		params  DeriveParams
+		wantErr string
+	}{
+		{"Empty master password", "", 16, deriveAll, site, "master password"},
+		{"Empty site", "secret", 16, deriveAll, DeriveParams{Site: " "}, "site"},
+		{"Zero length", "secret", 0, deriveAll, site, "length"},
+		{"Unknown version", "secret", 16, deriveAll, DeriveParams{Site: "example.com", Version: 2}, "version 2"},
+		{"Memorable", "secret", 6, PasswordConfig{Type: "memorable"}, site, "charset"},
+		{"Pattern", "secret", 16, PasswordConfig{Pattern: "Cvccvc"}, site, "charset"},
+		{"First characters", "secret", 16, PasswordConfig{IncludeLowers: true, FirstChars: "abc"}, site, "charset"},
+		{"Empty charset", "secret", 16, PasswordConfig{}, site, "empty"},
+		{"Too many required", "secret", 2, PasswordConfig{IncludeLowers: true, IncludeDigits: true, MinDigits: 3}, site, "too short"},
+		{"Required class excluded", "secret", 16, PasswordConfig{IncludeLowers: true, MinDigits: 1}, site, "not included"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Derive(tt.master, tt.length, tt.config, tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Derive() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package gofee
+
+import (
+	"embed"
+	"strings"
+	"sync"
+)
+
+// dictionaryFiles holds frequency ranked word lists used to estimate password strength,
+// taken from zxcvbn (MIT licensed, Copyright (c) 2012-2016 Dan Wheeler and Dropbox, Inc.).
+// Every file contains one lowercase word per line, the most frequent word first.
+//
+//go:embed dictionaries/*.txt
+var dictionaryFiles embed.FS
+
+// dictionary maps the words of a ranked word list to their rank, starting at 1.
+type dictionary struct {
+	name  string
+	ranks map[string]int
+}
+
+// dictionaryNames lists the embedded dictionaries in the order they are matched.
+var dictionaryNames = []string{"passwords", "english", "female_names", "male_names", "surnames"}
+
+// loadDictionaries parses the embedded dictionaries once, when they are first needed.
+var loadDictionaries = sync.OnceValue(func() []*dictionary {
+	dicts := make([]*dictionary, 0, len(dictionaryNames))
+
+	for _, name := range dictionaryNames {
+		data, err := dictionaryFiles.ReadFile("dictionaries/" + name + ".txt")
+		if err != nil {
+			// The dictionaries are embedded, so they are always available.
+			panic(err)
+		}
+		dicts = append(dicts, newDictionary(name, strings.Split(string(data), "\n")))
+	}
+
+	return dicts
+})
+
+// newDictionary ranks the words in the order they are given.
+// Words are compared in lowercase, empty words are skipped.
+func newDictionary(name string, words []string) *dictionary {
+	d := &dictionary{name: name, ranks: make(map[string]int, len(words))}
+
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" {
+			continue
+		}
+		if _, exists := d.ranks[word]; !exists {
+			d.ranks[word] = len(d.ranks) + 1
+		}
+	}
+
+	return d
+}
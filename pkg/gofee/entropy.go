@@ -19,3 +19,20 @@ func CalculateEntropy(charsetSize, passwordLength int) (float64, error) {
 	// Calculate entropy using the formula: entropy = passwordLength * log2(charsetSize)
 	return float64(passwordLength) * math.Log2(float64(charsetSize)), nil
 }
+
+// Strength returns a rating of a password with the given entropy (in bits).
+// The ratings are "very weak", "weak", "reasonable", "strong" and "very strong".
+func Strength(entropy float64) string {
+	switch {
+	case entropy < 28:
+		return "very weak"
+	case entropy < 36:
+		return "weak"
+	case entropy < 60:
+		return "reasonable"
+	case entropy < 128:
+		return "strong"
+	default:
+		return "very strong"
+	}
+}
@@ -77,3 +77,24 @@ func TestCalculateEntropy(t *testing.T) {
 		})
 	}
 }
+
+func TestStrength(t *testing.T) {
+	tests := []struct {
+		entropy float64
+		want    string
+	}{
+		{entropy: 0, want: "very weak"},
+		{entropy: 27.9, want: "very weak"},
+		{entropy: 28, want: "weak"},
+		{entropy: 36, want: "reasonable"},
+		{entropy: 60, want: "strong"},
+		{entropy: 104.9, want: "strong"},
+		{entropy: 128, want: "very strong"},
+	}
+
+	for _, tt := range tests {
+		if got := Strength(tt.entropy); got != tt.want {
+			t.Errorf("Strength(%v) = %q, want %q", tt.entropy, got, tt.want)
+		}
+	}
+}
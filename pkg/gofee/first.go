@@ -0,0 +1,70 @@
+package gofee
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// FirstCharGenerator wraps a PasswordGenerator and generates passwords again until their
+// first character is one of a set of characters.
+type FirstCharGenerator struct {
+	pg          PasswordGenerator
+	first       string
+	maxAttempts int
+	entropy     float64
+}
+
+// newFirstCharGenerator returns a FirstCharGenerator or an error if the passwords of pg cannot
+// start with any of the first characters.
+func newFirstCharGenerator(pg PasswordGenerator, first string) (*FirstCharGenerator, error) {
+	c, ok := pg.(interface{ Charset() string })
+	if !ok || c.Charset() == "" {
+		return nil, fmt.Errorf("the first character can only be restricted for passwords drawn from a charset")
+	}
+
+	allowed := keepChars(c.Charset(), first)
+	if allowed == "" {
+		return nil, fmt.Errorf("none of the first characters %q is part of the charset", first)
+	}
+
+	// A password drawn from the charset starts with one of the allowed characters with a
+	// probability of about len(allowed)/len(charset). Rejecting the others removes the bits
+	// of the first position that are no longer possible; the expected number of attempts
+	// is the inverse of that probability.
+	ratio := float64(len([]rune(c.Charset()))) / float64(len([]rune(allowed)))
+	return &FirstCharGenerator{
+		pg:          pg,
+		first:       allowed,
+		maxAttempts: 100 * int(math.Ceil(ratio)),
+		entropy:     math.Max(0, pg.Entropy()-math.Log2(ratio)),
+	}, nil
+}
+
+// Generate returns a new random password starting with one of the first characters.
+func (f *FirstCharGenerator) Generate() (string, error) {
+	for i := 0; i < f.maxAttempts; i++ {
+		password, err := f.pg.Generate()
+		if err != nil {
+			return "", err
+		}
+		for _, c := range password {
+			if strings.ContainsRune(f.first, c) {
+				return password, nil
+			}
+			break
+		}
+	}
+	return "", fmt.Errorf("no password starting with one of %q after %d attempts", f.first, f.maxAttempts)
+}
+
+// Entropy returns the entropy of the wrapped PasswordGenerator without the bits of the
+// first characters that were rejected.
+func (f *FirstCharGenerator) Entropy() float64 {
+	return f.entropy
+}
+
+// Charset returns the charset of the wrapped PasswordGenerator.
+func (f *FirstCharGenerator) Charset() string {
+	return f.pg.(interface{ Charset() string }).Charset()
+}
@@ -0,0 +1,90 @@
+package gofee
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestGenerateFirstChars checks that passwords always start with one of the first characters.
+func TestGenerateFirstChars(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+		config PasswordConfig
+		first  string // The characters the passwords may start with.
+	}{
+		{
+			name:   "Letters",
+			length: 12,
+			config: PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true, FirstChars: Lowers + Uppers},
+			first:  Lowers + Uppers,
+		},
+		{
+			name:   "Excluded first characters",
+			length: 8,
+			config: PasswordConfig{IncludeLowers: true, IncludeDigits: true, FirstChars: Uppers + Digits},
+			first:  Digits,
+		},
+		{
+			name:   "Constraints",
+			length: 10,
+			config: PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, MinDigits: 2, FirstChars: Uppers},
+			first:  Uppers,
+		},
+		{
+			name:   "Pattern",
+			length: 1,
+			config: PasswordConfig{Pattern: "x{8}", FirstChars: Digits},
+			first:  Digits,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				password, err := Generate(tt.length, tt.config)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if !strings.ContainsRune(tt.first, []rune(password)[0]) {
+					t.Errorf("Generate() = %q, want first character in %q", password, tt.first)
+				}
+			}
+		})
+	}
+}
+
+// TestFirstCharsEntropy checks that the rejected first characters are removed from the entropy.
+func TestFirstCharsEntropy(t *testing.T) {
+	result, err := GenerateResult(10, PasswordConfig{IncludeLowers: true, IncludeDigits: true, FirstChars: Lowers})
+	if err != nil {
+		t.Fatalf("GenerateResult() error = %v", err)
+	}
+
+	// 26 of the 36 characters remain for the first position.
+	want := 9*math.Log2(36) + math.Log2(26)
+	if math.Abs(result.Entropy-want) > 1e-9 {
+		t.Errorf("GenerateResult() entropy = %v, want %v", result.Entropy, want)
+	}
+}
+
+// TestFirstCharsErrors checks the configs whose passwords cannot start with the first characters.
+func TestFirstCharsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config PasswordConfig
+	}{
+		{"No overlap", PasswordConfig{IncludeDigits: true, FirstChars: Lowers}},
+		{"Passphrase", PasswordConfig{Type: "memorable", FirstChars: Uppers}},
+		{"Accented letter not in charset", PasswordConfig{IncludeLowers: true, FirstChars: "é"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(6, tt.config); err == nil {
+				t.Errorf("Generate() returned no error")
+			}
+		})
+	}
+}
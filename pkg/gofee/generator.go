@@ -1,26 +1,231 @@
 package gofee
 
 import (
+	"crypto/rand"
 	"fmt"
+	"io"
+	"sync"
 )
 
+// Generator generates passwords using the randomness read from an io.Reader.
+// The zero value is ready to use and reads from crypto/rand.Reader.
+// A Generator is safe for concurrent use.
+type Generator struct {
+	rand io.Reader
+
+	// The random bytes are read in blocks, see readRandom.
+	mu       sync.Mutex
+	buf      []byte
+	buffered []byte    // The unused part of buf.
+	source   io.Reader // The reader buf was filled from.
+}
+
+// defaultGenerator is used by the package-level functions.
+var defaultGenerator = &Generator{}
+
+// NewGenerator returns a Generator reading its randomness from r.
+// If r is nil, the Generator reads from crypto/rand.Reader.
+func NewGenerator(r io.Reader) *Generator {
+	return &Generator{rand: r}
+}
+
+// reader returns the source of randomness of the Generator.
+// crypto/rand.Reader is looked up on every call, so replacing it takes effect immediately.
+func (g *Generator) reader() io.Reader {
+	if g.rand == nil {
+		return rand.Reader
+	}
+	return g.rand
+}
+
+// Result holds a generated password together with the charset it was drawn from and its entropy.
+type Result struct {
+	Password string  // The generated password.
+	Charset  string  // The characters the password was drawn from, empty for passphrases.
+	Entropy  float64 // The entropy of the password in bits.
+}
+
+// Generate creates a random password of the specified length using the default Generator,
+// which reads its randomness from crypto/rand.Reader.
+func Generate(length int, config PasswordConfig) (string, error) {
+	return defaultGenerator.Generate(length, config)
+}
+
 // Generate creates a random password of the specified length using the given PasswordConfig.
 // It returns the generated password or an error if the length is invalid or password generation fails.
-func Generate(length int, config PasswordConfig) (string, error) {
+func (g *Generator) Generate(length int, config PasswordConfig) (string, error) {
+	result, err := g.GenerateResult(length, config)
+	if err != nil {
+		return "", err
+	}
+	return result.Password, nil
+}
+
+// GenerateResult creates a random password like Generate using the default Generator,
+// which reads its randomness from crypto/rand.Reader.
+func GenerateResult(length int, config PasswordConfig) (Result, error) {
+	return defaultGenerator.GenerateResult(length, config)
+}
+
+// GenerateResult creates a random password of the specified length using the given PasswordConfig.
+// It returns the password along with its charset and entropy, or an error if the length is invalid
+// or password generation fails. GenerateResult shares no state between calls, so a Generator is
+// safe for concurrent use as long as its reader is.
+func (g *Generator) GenerateResult(length int, config PasswordConfig) (Result, error) {
+	pg, err := g.New(length, config)
+	if err != nil {
+		return Result{}, err
+	}
+	return generateResult(pg)
+}
+
+// GenerateN creates n random passwords like Generator.GenerateN using the default Generator,
+// which reads its randomness from crypto/rand.Reader.
+func GenerateN(n, length int, config PasswordConfig, fn func(Result) error) error {
+	return defaultGenerator.GenerateN(n, length, config, fn)
+}
+
+// GenerateN creates n random passwords of the specified length using the given PasswordConfig.
+// The charset is built only once and every password is passed to fn as soon as it is generated,
+// so no more than one password is held in memory. GenerateN stops at the first error returned
+// by fn or by the password generation.
+func (g *Generator) GenerateN(n, length int, config PasswordConfig, fn func(Result) error) error {
+	// Return an error if the count is invalid.
+	if n <= 0 {
+		return fmt.Errorf("count must be greater than 0")
+	}
+
+	pg, err := g.New(length, config)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+		result, err := generateResult(pg)
+		if err != nil {
+			return err
+		}
+		if err := fn(result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PasswordGenerator generates passwords of one kind and length, such as PINs,
+// charset passwords, constrained passwords or passphrases.
+type PasswordGenerator interface {
+	// Generate returns a new random password.
+	Generate() (string, error)
+	// Entropy returns the entropy (in bits) of the generated passwords.
+	Entropy() float64
+}
+
+// New returns the PasswordGenerator for the given length and PasswordConfig,
+// which uses the default Generator as its source of randomness.
+func New(length int, config PasswordConfig) (PasswordGenerator, error) {
+	return defaultGenerator.New(length, config)
+}
+
+// New validates the length and PasswordConfig and returns the PasswordGenerator
+// producing the requested kind of passwords with the randomness of g.
+// The charset and the entropy are computed once, so the returned PasswordGenerator
+// can be reused for any number of passwords.
+func (g *Generator) New(length int, config PasswordConfig) (PasswordGenerator, error) {
+	config = config.normalize()
+
+	pg, err := g.newPasswordGenerator(length, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Restrict the first character, if requested.
+	if config.FirstChars != "" {
+		pg, err = newFirstCharGenerator(pg, config.FirstChars)
+		if err != nil {
+			return nil, fmt.Errorf("invalid constraints: %v", err)
+		}
+	}
+
+	if config.BreachDB == nil {
+		return pg, nil
+	}
+
+	// Reject the passwords found in the breach database.
+	return &BreachCheckedGenerator{pg: pg, db: config.BreachDB}, nil
+}
+
+// newPasswordGenerator returns the PasswordGenerator for the kind of passwords of the config.
+func (g *Generator) newPasswordGenerator(length int, config PasswordConfig) (PasswordGenerator, error) {
+	// The length of a pattern is given by the pattern itself.
+	if config.Pattern != "" {
+		return g.newPatternGenerator(config)
+	}
+
 	// Check if the provided length is valid (i.e., greater than 0).
 	if length <= 0 {
 		// Return an error if the length is not valid.
-		return "", fmt.Errorf("length must be greater than 0")
+		return nil, fmt.Errorf("length must be greater than 0")
 	}
 
-	// Call MapToCharset to generate a password based on the length and configuration.
-	// This function generates a password by mapping random numbers to characters from the charset.
-	password, err := MapToCharset(length, config)
+	// Memorable passwords are passphrases, where the length is the number of words.
+	// The custom symbols and the excluded characters apply to the appended digits and symbols.
+	if config.Type == "memorable" {
+		passphrase, err := config.Passphrase.withPasswordConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		return g.newPassphraseGenerator(length, passphrase)
+	}
+
+	// Pronounceable passwords are built from syllables instead of a charset.
+	if config.Type == "pronounceable" {
+		return g.newPronounceableGenerator(length, config)
+	}
+
+	// Check the custom characters before they are used.
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid charset: %v", err)
+	}
+
+	// Build the charset once, so the passwords and their entropy are based on the same characters.
+	charset := BuildCharset(config)
+
+	// Return an error if no characters are available in the charset.
+	if len(charset) == 0 {
+		return nil, fmt.Errorf("error mapping number to charset: charset is empty")
+	}
+
+	// Collect the per-class minimum counts, if there are any.
+	constraints, err := config.constraints()
 	if err != nil {
-		// Return an error if MapToCharset fails, including the specific error message.
-		return "", fmt.Errorf("error mapping number to charset: %v", err)
+		return nil, fmt.Errorf("invalid constraints: %v", err)
+	}
+
+	switch {
+	case constraints != nil:
+		return g.newConstrainedGenerator(length, charset, constraints)
+	case config.Type == "pin":
+		return &PinGenerator{CharsetGenerator{g: g, length: length, charset: charset}}, nil
+	default:
+		return &CharsetGenerator{g: g, length: length, charset: charset}, nil
+	}
+}
+
+// generateResult creates a single password with the PasswordGenerator and describes it in a Result.
+func generateResult(pg PasswordGenerator) (Result, error) {
+	password, err := pg.Generate()
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Password: password, Entropy: pg.Entropy()}
+
+	// Only passwords drawn from a charset report it.
+	if c, ok := pg.(interface{ Charset() string }); ok {
+		result.Charset = c.Charset()
 	}
 
-	// Return the successfully generated password.
-	return password, nil
+	return result, nil
 }
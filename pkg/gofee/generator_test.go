@@ -1,6 +1,16 @@
 package gofee
 
-import "testing"
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+)
 
 // Benchmark for password generation, tests perfomance in parallel.
 // The function runs password generation in multiple goroutines, simulating real-world load.
@@ -61,7 +71,7 @@ func TestGenerateRandomness(t *testing.T) {
 	}
 
 	// Estimate the expected count for each character, with a 10% tolerance.
-	expectedCount := numPws * length / len(Charset)
+	expectedCount := numPws * length / len(All)
 	tolerance := expectedCount / 10
 
 	// Verify that the character frequencies are within the expected range.
@@ -220,3 +230,315 @@ func TestGenerate(t *testing.T) {
 		})
 	}
 }
+
+// drbg is a deterministic random bit generator for tests.
+// It produces the SHA-256 hashes of the seed followed by an increasing counter.
+type drbg struct {
+	seed    []byte
+	counter uint64
+	buf     []byte
+}
+
+// Read fills p with the next deterministic bytes of the generator.
+func (d *drbg) Read(p []byte) (int, error) {
+	for n := 0; n < len(p); {
+		if len(d.buf) == 0 {
+			block := sha256.Sum256(binary.BigEndian.AppendUint64(d.seed, d.counter))
+			d.buf = block[:]
+			d.counter++
+		}
+		c := copy(p[n:], d.buf)
+		d.buf = d.buf[c:]
+		n += c
+	}
+	return len(p), nil
+}
+
+// TestGeneratorDeterministic checks that Generators with equally seeded readers produce the same passwords.
+func TestGeneratorDeterministic(t *testing.T) {
+	configs := []PasswordConfig{
+		{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true},
+		{Type: "pin"},
+		{Type: "memorable", Passphrase: PassphraseConfig{Separator: "-", AddDigit: true, AddSymbol: true}},
+	}
+
+	for _, config := range configs {
+		a := NewGenerator(&drbg{seed: []byte("seed")})
+		b := NewGenerator(&drbg{seed: []byte("seed")})
+		c := NewGenerator(&drbg{seed: []byte("other seed")})
+
+		pwA, errA := a.Generate(16, config)
+		pwB, errB := b.Generate(16, config)
+		pwC, errC := c.Generate(16, config)
+		if errA != nil || errB != nil || errC != nil {
+			t.Fatalf("Generate() errors = %v, %v, %v", errA, errB, errC)
+		}
+
+		if pwA != pwB {
+			t.Errorf("Generate() with equal seeds = %q and %q, want equal passwords", pwA, pwB)
+		}
+		if pwA == pwC {
+			t.Errorf("Generate() with different seeds = %q, want different passwords", pwA)
+		}
+	}
+}
+
+// TestGeneratorReaderError checks that errors of the reader are returned by every kind of password.
+func TestGeneratorReaderError(t *testing.T) {
+	g := NewGenerator(&errReader{})
+
+	configs := []PasswordConfig{
+		{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true},
+		{Type: "pin"},
+		{Type: "memorable"},
+	}
+
+	for _, config := range configs {
+		_, err := g.Generate(8, config)
+		if err == nil || !strings.Contains(err.Error(), "mocked error from rand.Reader") {
+			t.Errorf("Generate() with type %q error = %v, want mocked error", config.Type, err)
+		}
+	}
+}
+
+// TestNewGeneratorDefault checks that a Generator without a reader falls back to crypto/rand.Reader.
+func TestNewGeneratorDefault(t *testing.T) {
+	if got := NewGenerator(nil).reader(); got != rand.Reader {
+		t.Errorf("NewGenerator(nil).reader() = %v, want crypto/rand.Reader", got)
+	}
+}
+
+// TestGenerateResult checks that the result carries the charset and entropy of the generated password.
+func TestGenerateResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		length      int
+		config      PasswordConfig
+		wantCharset string
+		wantEntropy float64
+	}{
+		{
+			name:        "All classes",
+			length:      16,
+			config:      PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true},
+			wantCharset: All,
+			wantEntropy: 16 * math.Log2(float64(len(All))),
+		},
+		{
+			name:        "Pin",
+			length:      4,
+			config:      PasswordConfig{Type: "pin"},
+			wantCharset: Digits,
+			wantEntropy: 4 * math.Log2(10),
+		},
+		{
+			name:        "Custom charset with repeated characters",
+			length:      10,
+			config:      PasswordConfig{CustomCharset: "aabbcc"},
+			wantCharset: "abc",
+			wantEntropy: 10 * math.Log2(3),
+		},
+		{
+			name:        "Without ambiguous characters",
+			length:      12,
+			config:      PasswordConfig{IncludeLowers: true, IncludeDigits: true, ExcludeAmbiguous: true},
+			wantCharset: "abcdefghijkmnpqrstuvwxyz" + "34679",
+			wantEntropy: 12 * math.Log2(29),
+		},
+		{
+			name:        "Memorable",
+			length:      6,
+			config:      PasswordConfig{Type: "memorable", Passphrase: PassphraseConfig{Separator: " "}},
+			wantCharset: "",
+			wantEntropy: 6 * math.Log2(float64(len(Wordlist))),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateResult(tt.length, tt.config)
+			if err != nil {
+				t.Fatalf("GenerateResult() error = %v", err)
+			}
+
+			if got.Charset != tt.wantCharset {
+				t.Errorf("GenerateResult() charset = %q, want %q", got.Charset, tt.wantCharset)
+			}
+			if math.Abs(got.Entropy-tt.wantEntropy) > 1e-9 {
+				t.Errorf("GenerateResult() entropy = %v, want %v", got.Entropy, tt.wantEntropy)
+			}
+			for _, c := range got.Password {
+				if tt.wantCharset != "" && !Contains(tt.wantCharset, c) {
+					t.Errorf("GenerateResult() password contains invalid character = %q", c)
+				}
+			}
+		})
+	}
+}
+
+// TestGenerateConcurrent hammers Generate from many goroutines with different configurations.
+// Run it with -race to detect shared mutable state.
+func TestGenerateConcurrent(t *testing.T) {
+	configs := []PasswordConfig{
+		{IncludeLowers: true},
+		{IncludeDigits: true},
+		{IncludeUppers: true, IncludeSymbols: true},
+		{Type: "pin"},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(config PasswordConfig) {
+			defer wg.Done()
+
+			for j := 0; j < 200; j++ {
+				result, err := GenerateResult(12, config)
+				if err != nil {
+					t.Errorf("GenerateResult() error = %v", err)
+					return
+				}
+
+				// Every password must be drawn from the charset of its own configuration.
+				if result.Charset != BuildCharset(config) {
+					t.Errorf("GenerateResult() charset = %q, want %q", result.Charset, BuildCharset(config))
+				}
+				for _, c := range result.Password {
+					if !Contains(result.Charset, c) {
+						t.Errorf("GenerateResult() password %q contains %q outside of its charset", result.Password, c)
+					}
+				}
+			}
+		}(configs[i%len(configs)])
+	}
+	wg.Wait()
+}
+
+// TestGenerateN tests the batch generation of passwords.
+func TestGenerateN(t *testing.T) {
+	config := PasswordConfig{IncludeLowers: true, IncludeDigits: true}
+
+	// Every generated password is passed to the callback.
+	var results []Result
+	err := GenerateN(100, 12, config, func(r Result) error {
+		results = append(results, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateN() error = %v", err)
+	}
+	if len(results) != 100 {
+		t.Fatalf("GenerateN() produced %d passwords, want 100", len(results))
+	}
+	for _, r := range results {
+		if len(r.Password) != 12 || r.Charset != Lowers+Digits {
+			t.Errorf("GenerateN() result = %+v, want 12 characters from %q", r, Lowers+Digits)
+		}
+	}
+
+	// The first error of the callback stops the generation.
+	var calls int
+	errStop := errors.New("stop")
+	err = GenerateN(100, 12, config, func(r Result) error {
+		calls++
+		if calls == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || calls != 3 {
+		t.Errorf("GenerateN() error = %v after %d calls, want %v after 3 calls", err, calls, errStop)
+	}
+
+	// Invalid counts and configurations are rejected before any password is generated.
+	for _, n := range []int{0, -1} {
+		if err := GenerateN(n, 12, config, func(Result) error { return nil }); err == nil {
+			t.Errorf("GenerateN() with count %d error = nil, want error", n)
+		}
+	}
+	if err := GenerateN(10, 12, PasswordConfig{}, func(Result) error {
+		t.Fatal("GenerateN() called fn for an empty charset")
+		return nil
+	}); err == nil {
+		t.Errorf("GenerateN() with empty charset error = nil, want error")
+	}
+}
+
+// TestNewEntropy checks the kind and the exact entropy of the PasswordGenerator for every mode.
+func TestNewEntropy(t *testing.T) {
+	tests := []struct {
+		name        string
+		length      int
+		config      PasswordConfig
+		wantType    PasswordGenerator
+		wantEntropy float64
+	}{
+		{
+			name:        "Pin",
+			length:      6,
+			config:      PasswordConfig{Type: "pin"},
+			wantType:    &PinGenerator{},
+			wantEntropy: 6 * math.Log2(10),
+		},
+		{
+			name:        "Pin without excluded digits",
+			length:      4,
+			config:      PasswordConfig{Type: "pin", ExcludeChars: "0"},
+			wantType:    &PinGenerator{},
+			wantEntropy: 4 * math.Log2(9),
+		},
+		{
+			name:        "Charset",
+			length:      16,
+			config:      PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true},
+			wantType:    &CharsetGenerator{},
+			wantEntropy: 16 * math.Log2(90),
+		},
+		{
+			name:        "Passphrase",
+			length:      5,
+			config:      PasswordConfig{Type: "memorable", Passphrase: PassphraseConfig{AddDigit: true}},
+			wantType:    &PassphraseGenerator{},
+			wantEntropy: 5*math.Log2(7776) + math.Log2(10) + math.Log2(5),
+		},
+		{
+			name:        "Constrained",
+			length:      10,
+			config:      PasswordConfig{IncludeLowers: true, IncludeDigits: true, MinDigits: 2},
+			wantType:    &ConstrainedGenerator{},
+			wantEntropy: 2*math.Log2(10) + 8*math.Log2(36),
+		},
+		{
+			name:        "Constrained pin",
+			length:      4,
+			config:      PasswordConfig{Type: "pin", RequireAll: true},
+			wantType:    &ConstrainedGenerator{},
+			wantEntropy: 4 * math.Log2(10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pg, err := New(tt.length, tt.config)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if got, want := fmt.Sprintf("%T", pg), fmt.Sprintf("%T", tt.wantType); got != want {
+				t.Errorf("New() = %s, want %s", got, want)
+			}
+			if got := pg.Entropy(); math.Abs(got-tt.wantEntropy) > 1e-9 {
+				t.Errorf("Entropy() = %v, want %v", got, tt.wantEntropy)
+			}
+
+			// The result of a generated password reports the entropy of its generator.
+			result, err := generateResult(pg)
+			if err != nil {
+				t.Fatalf("generateResult() error = %v", err)
+			}
+			if result.Entropy != pg.Entropy() {
+				t.Errorf("generateResult() entropy = %v, want %v", result.Entropy, pg.Entropy())
+			}
+		})
+	}
+}
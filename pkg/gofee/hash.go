@@ -0,0 +1,437 @@
+package gofee
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Password hashing algorithms.
+const (
+	HashBcrypt      = "bcrypt"       // $2a$ hashes, as used by htpasswd and many web frameworks.
+	HashArgon2id    = "argon2id"     // $argon2id$ hashes in the PHC string format.
+	HashScrypt      = "scrypt"       // $scrypt$ hashes in the PHC string format.
+	HashSHA512Crypt = "sha512-crypt" // $6$ hashes, as used by /etc/shadow.
+	HashPBKDF2      = "pbkdf2"       // $pbkdf2-sha256$ hashes in the PHC string format.
+)
+
+// HashAlgorithms lists the password hashing algorithms in the order they are documented.
+var HashAlgorithms = []string{HashBcrypt, HashArgon2id, HashScrypt, HashSHA512Crypt, HashPBKDF2}
+
+// Default costs of the hashing algorithms, following the recommendations of OWASP and RFC 9106.
+const (
+	BcryptCost        = 12
+	Argon2idPasses    = 3
+	Argon2idMemory    = 64 * 1024 // 64 MiB in KiB.
+	Argon2idLanes     = 4
+	ScryptLogN        = 17 // N = 2^17 = 131072, which uses 128 MiB with r = 8.
+	ScryptParallelism = 1
+	SHA512CryptRounds = 656000
+	PBKDF2Iterations  = 600000
+)
+
+// Limits of the rounds of sha512-crypt, as specified. Rounds below the minimum are raised to it.
+const (
+	SHA512CryptMinRounds = 1000
+	SHA512CryptMaxRounds = 999999999
+)
+
+// Limits of the costs, so that hashes read from untrusted input cannot exhaust memory or time.
+// Each of them takes a few seconds at most.
+const (
+	HashMaxMemory          = 4 << 20 // 4 GiB in KiB, for Argon2id and scrypt.
+	Argon2idMaxPasses      = 100
+	ScryptMaxWork          = 1 << 24 // N * r * p, 16 times the default cost.
+	SHA512CryptRoundsLimit = 10000000
+	PBKDF2MaxIterations    = 10000000
+)
+
+// Sizes of salts and hashes in bytes.
+const (
+	hashSaltSize = 16
+	hashKeySize  = 32
+
+	scryptBlockSize    = 8
+	sha512CryptSaltLen = 16 // The maximum number of characters of sha512-crypt salts.
+)
+
+// cryptAlphabet is the alphabet of the base64 encoding of crypt hashes and salts.
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// errMalformedHash is returned when a hash cannot be parsed.
+var errMalformedHash = errors.New("malformed hash")
+
+// HashConfig describes how a password is hashed. Costs which are 0 have their default values.
+type HashConfig struct {
+	Algorithm   string // One of HashAlgorithms.
+	Cost        int    // The cost of bcrypt, or log2 of N of scrypt.
+	Rounds      int    // The rounds of sha512-crypt, the iterations of PBKDF2 or the passes of Argon2id.
+	Memory      uint32 // The memory of Argon2id in KiB.
+	Parallelism int    // The lanes of Argon2id, or p of scrypt.
+}
+
+// HashPassword hashes a password for storage using the default Generator.
+func HashPassword(password string, config HashConfig) (string, error) {
+	return defaultGenerator.HashPassword(password, config)
+}
+
+// HashPassword hashes a password for storage, with a random salt drawn from the Generator.
+// bcrypt draws its salt from crypto/rand.Reader, as its package does not accept a reader.
+func (g *Generator) HashPassword(password string, config HashConfig) (string, error) {
+	orDefault := func(value, def int) int {
+		if value == 0 {
+			return def
+		}
+		return value
+	}
+
+	switch config.Algorithm {
+	case HashBcrypt:
+		cost := orDefault(config.Cost, BcryptCost)
+		if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+			return "", fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		h, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+		if err != nil {
+			return "", fmt.Errorf("error hashing password: %v", err)
+		}
+		return string(h), nil
+
+	case HashArgon2id:
+		passes := orDefault(config.Rounds, Argon2idPasses)
+		lanes := orDefault(config.Parallelism, Argon2idLanes)
+		memory := config.Memory
+		if memory == 0 {
+			memory = Argon2idMemory
+		}
+		if passes < 1 || passes > Argon2idMaxPasses || lanes < 1 || lanes > 255 || memory < 8*uint32(lanes) || memory > HashMaxMemory {
+			return "", fmt.Errorf("argon2id needs 1 to %d passes, 1 to 255 lanes and 8 KiB of memory per lane, up to %d KiB", Argon2idMaxPasses, HashMaxMemory)
+		}
+		salt, err := g.randomBytes(hashSaltSize)
+		if err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, uint32(passes), memory, uint8(lanes), hashKeySize)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, memory, passes, lanes, phcEncode(salt), phcEncode(key)), nil
+
+	case HashScrypt:
+		logN := orDefault(config.Cost, ScryptLogN)
+		p := orDefault(config.Parallelism, ScryptParallelism)
+		if !validScrypt(logN, scryptBlockSize, p) {
+			return "", fmt.Errorf("scrypt needs a cost of at least 1, a parallelism of at least 1, at most %d KiB of memory and N * r * p of at most %d", HashMaxMemory, ScryptMaxWork)
+		}
+		salt, err := g.randomBytes(hashSaltSize)
+		if err != nil {
+			return "", err
+		}
+		key, err := scrypt.Key([]byte(password), salt, 1<<logN, scryptBlockSize, p, hashKeySize)
+		if err != nil {
+			return "", fmt.Errorf("error hashing password: %v", err)
+		}
+		return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s", logN, scryptBlockSize, p, phcEncode(salt), phcEncode(key)), nil
+
+	case HashSHA512Crypt:
+		rounds := orDefault(config.Rounds, SHA512CryptRounds)
+		if rounds < SHA512CryptMinRounds || rounds > SHA512CryptRoundsLimit {
+			return "", fmt.Errorf("sha512-crypt rounds must be between %d and %d", SHA512CryptMinRounds, SHA512CryptRoundsLimit)
+		}
+		salt, err := g.mapToCharset(sha512CryptSaltLen, cryptAlphabet)
+		if err != nil {
+			return "", err
+		}
+		return sha512Crypt([]byte(password), []byte(salt), rounds, true), nil
+
+	case HashPBKDF2:
+		iterations := orDefault(config.Rounds, PBKDF2Iterations)
+		if iterations < 1 || iterations > PBKDF2MaxIterations {
+			return "", fmt.Errorf("PBKDF2 needs 1 to %d iterations", PBKDF2MaxIterations)
+		}
+		salt, err := g.randomBytes(hashSaltSize)
+		if err != nil {
+			return "", err
+		}
+		key := pbkdf2.Key([]byte(password), salt, iterations, hashKeySize, sha256.New)
+		return fmt.Sprintf("$pbkdf2-sha256$i=%d$%s$%s", iterations, phcEncode(salt), phcEncode(key)), nil
+	}
+
+	return "", fmt.Errorf("unknown hash algorithm %q (supported: %s)", config.Algorithm, strings.Join(HashAlgorithms, ", "))
+}
+
+// VerifyPassword reports whether the password matches a hash of one of the HashAlgorithms. It
+// returns an error if the hash is not supported or malformed.
+func VerifyPassword(password, hashed string) (bool, error) {
+	switch {
+	case strings.HasPrefix(hashed, "$2a$"), strings.HasPrefix(hashed, "$2b$"), strings.HasPrefix(hashed, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	case strings.HasPrefix(hashed, "$argon2id$"):
+		return verifyArgon2id(password, hashed)
+	case strings.HasPrefix(hashed, "$scrypt$"):
+		return verifyScrypt(password, hashed)
+	case strings.HasPrefix(hashed, "$6$"):
+		return verifySHA512Crypt(password, hashed)
+	case strings.HasPrefix(hashed, "$pbkdf2-"):
+		return verifyPBKDF2(password, hashed)
+	}
+	return false, fmt.Errorf("unsupported hash (supported: %s)", strings.Join(HashAlgorithms, ", "))
+}
+
+// verifyArgon2id verifies a hash like $argon2id$v=19$m=65536,t=3,p=4$salt$key.
+func verifyArgon2id(password, hashed string) (bool, error) {
+	fields := strings.Split(hashed, "$")
+	if len(fields) != 6 || fields[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return false, errMalformedHash
+	}
+	params, err := phcParams(fields[3], "m", "t", "p")
+	if err != nil || params[0] < 8*params[2] || params[0] > HashMaxMemory || params[1] < 1 || params[1] > Argon2idMaxPasses || params[2] < 1 || params[2] > 255 {
+		return false, errMalformedHash
+	}
+	salt, key, err := phcSaltAndKey(fields[4], fields[5])
+	if err != nil {
+		return false, err
+	}
+	got := argon2.IDKey([]byte(password), salt, uint32(params[1]), uint32(params[0]), uint8(params[2]), uint32(len(key)))
+	return subtle.ConstantTimeCompare(got, key) == 1, nil
+}
+
+// verifyScrypt verifies a hash like $scrypt$ln=17,r=8,p=1$salt$key.
+func verifyScrypt(password, hashed string) (bool, error) {
+	fields := strings.Split(hashed, "$")
+	if len(fields) != 5 {
+		return false, errMalformedHash
+	}
+	params, err := phcParams(fields[2], "ln", "r", "p")
+	if err != nil || !validScrypt(params[0], params[1], params[2]) {
+		return false, errMalformedHash
+	}
+	salt, key, err := phcSaltAndKey(fields[3], fields[4])
+	if err != nil {
+		return false, err
+	}
+	got, err := scrypt.Key([]byte(password), salt, 1<<params[0], params[1], params[2], len(key))
+	if err != nil {
+		return false, errMalformedHash
+	}
+	return subtle.ConstantTimeCompare(got, key) == 1, nil
+}
+
+// validScrypt reports whether scrypt accepts the parameters without using more than HashMaxMemory
+// and ScryptMaxWork. scrypt.Key panics on a block size or parallelism of 0.
+func validScrypt(logN, r, p int) bool {
+	if logN < 1 || logN > 30 || r < 1 || p < 1 {
+		return false
+	}
+	// scrypt uses 128 * r * N bytes, or r * N / 8 KiB, and takes time proportional to N * r * p.
+	// The products are checked by division, so large values cannot overflow them.
+	return r <= HashMaxMemory*8>>logN && p <= ScryptMaxWork>>logN/r
+}
+
+// verifyPBKDF2 verifies a hash like $pbkdf2-sha256$i=600000$salt$key. SHA-512 is supported as well.
+func verifyPBKDF2(password, hashed string) (bool, error) {
+	fields := strings.Split(hashed, "$")
+	if len(fields) != 5 {
+		return false, errMalformedHash
+	}
+	var newHash func() hash.Hash
+	switch fields[1] {
+	case "pbkdf2-sha256":
+		newHash = sha256.New
+	case "pbkdf2-sha512":
+		newHash = sha512.New
+	default:
+		return false, errMalformedHash
+	}
+	params, err := phcParams(fields[2], "i")
+	if err != nil || params[0] < 1 || params[0] > PBKDF2MaxIterations {
+		return false, errMalformedHash
+	}
+	salt, key, err := phcSaltAndKey(fields[3], fields[4])
+	if err != nil {
+		return false, err
+	}
+	got := pbkdf2.Key([]byte(password), salt, params[0], len(key), newHash)
+	return subtle.ConstantTimeCompare(got, key) == 1, nil
+}
+
+// verifySHA512Crypt verifies a hash like $6$rounds=5000$salt$hash. Too few rounds are raised to
+// the minimum, like crypt(3) does, but hashes with more than SHA512CryptRoundsLimit are rejected.
+func verifySHA512Crypt(password, hashed string) (bool, error) {
+	fields := strings.Split(hashed, "$")
+	rounds, explicit := 5000, false
+	if len(fields) == 5 && strings.HasPrefix(fields[2], "rounds=") {
+		var err error
+		if rounds, err = strconv.Atoi(strings.TrimPrefix(fields[2], "rounds=")); err != nil {
+			return false, errMalformedHash
+		}
+		if rounds > SHA512CryptRoundsLimit {
+			return false, errMalformedHash
+		}
+		rounds = max(rounds, SHA512CryptMinRounds)
+		explicit = true
+		fields = append(fields[:2], fields[3:]...)
+	}
+	if len(fields) != 4 || len(fields[3]) != 86 {
+		return false, errMalformedHash
+	}
+
+	got := sha512Crypt([]byte(password), []byte(fields[2]), rounds, explicit)
+	// Compare the hashes only, as a raised number of rounds is written differently.
+	want := fields[3]
+	return subtle.ConstantTimeCompare([]byte(got[len(got)-86:]), []byte(want)) == 1, nil
+}
+
+// sha512Crypt computes a SHA-crypt hash with SHA-512 as specified by Ulrich Drepper in
+// "Unix crypt using SHA-256 and SHA-512". The salt is truncated to 16 characters. The
+// rounds are written to the hash if explicit is set.
+func sha512Crypt(password, salt []byte, rounds int, explicit bool) string {
+	if len(salt) > sha512CryptSaltLen {
+		salt = salt[:sha512CryptSaltLen]
+	}
+
+	// Digest B of the password, the salt and the password.
+	b := sha512.New()
+	b.Write(password)
+	b.Write(salt)
+	b.Write(password)
+	digestB := b.Sum(nil)
+
+	// Digest A of the password, the salt and digest B repeated to the length of the password,
+	// followed by digest B or the password for every bit of the length of the password.
+	a := sha512.New()
+	a.Write(password)
+	a.Write(salt)
+	a.Write(repeatBytes(digestB, len(password)))
+	for n := len(password); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			a.Write(digestB)
+		} else {
+			a.Write(password)
+		}
+	}
+	digestA := a.Sum(nil)
+
+	// Sequence P is the digest of the password repeated once per byte of the password.
+	dp := sha512.New()
+	for i := 0; i < len(password); i++ {
+		dp.Write(password)
+	}
+	p := repeatBytes(dp.Sum(nil), len(password))
+
+	// Sequence S is the digest of the salt repeated 16 + digestA[0] times.
+	ds := sha512.New()
+	for i := 0; i < 16+int(digestA[0]); i++ {
+		ds.Write(salt)
+	}
+	s := repeatBytes(ds.Sum(nil), len(salt))
+
+	c := digestA
+	h := sha512.New()
+	for i := 0; i < rounds; i++ {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(p)
+		}
+		c = h.Sum(c[:0])
+	}
+
+	var out strings.Builder
+	out.WriteString("$6$")
+	if explicit {
+		fmt.Fprintf(&out, "rounds=%d$", rounds)
+	}
+	out.Write(salt)
+	out.WriteByte('$')
+
+	// The bytes of the digest are encoded in groups of three in this order.
+	order := [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4}, {47, 5, 26}, {6, 27, 48},
+		{28, 49, 7}, {50, 8, 29}, {9, 30, 51}, {31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13},
+		{56, 14, 35}, {15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19}, {62, 20, 41},
+	}
+	encode := func(w uint32, n int) {
+		for ; n > 0; n-- {
+			out.WriteByte(cryptAlphabet[w&0x3f])
+			w >>= 6
+		}
+	}
+	for _, o := range order {
+		encode(uint32(c[o[0]])<<16|uint32(c[o[1]])<<8|uint32(c[o[2]]), 4)
+	}
+	encode(uint32(c[63]), 2)
+	return out.String()
+}
+
+// repeatBytes repeats b to n bytes.
+func repeatBytes(b []byte, n int) []byte {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		out = append(out, b[:min(len(b), n-len(out))]...)
+	}
+	return out
+}
+
+// phcEncode encodes salts and keys of PHC strings as base64 without padding.
+func phcEncode(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+// phcParams parses the parameters of a PHC string, such as m=65536,t=3,p=4, in the given order.
+func phcParams(s string, names ...string) ([]int, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != len(names) {
+		return nil, errMalformedHash
+	}
+	values := make([]int, len(names))
+	for i, field := range fields {
+		name, value, ok := strings.Cut(field, "=")
+		if !ok || name != names[i] {
+			return nil, errMalformedHash
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errMalformedHash
+		}
+		values[i] = n
+	}
+	return values, nil
+}
+
+// phcSaltAndKey decodes the salt and the key of a PHC string.
+func phcSaltAndKey(salt, key string) ([]byte, []byte, error) {
+	s, err := base64.RawStdEncoding.DecodeString(salt)
+	if err != nil {
+		return nil, nil, errMalformedHash
+	}
+	k, err := base64.RawStdEncoding.DecodeString(key)
+	if err != nil || len(k) == 0 {
+		return nil, nil, errMalformedHash
+	}
+	return s, k, nil
+}
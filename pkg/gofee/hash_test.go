@@ -0,0 +1,201 @@
+package gofee
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestSHA512Crypt checks the test vectors of the specification of SHA-crypt.
+func TestSHA512Crypt(t *testing.T) {
+	tests := []struct {
+		password string
+		salt     string
+		rounds   int
+		explicit bool
+		want     string
+	}{
+		{"Hello world!", "saltstring", 5000, false, "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+		{"Hello world!", "saltstringsaltstring", 10000, true, "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."},
+		{"This is just a test", "toolongsaltstring", 5000, true, "$6$rounds=5000$toolongsaltstrin$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0"},
+		{"a very much longer text to encrypt.  This one even stretches over morethan one line.", "anotherlongsaltstring", 1400, true, "$6$rounds=1400$anotherlongsalts$POfYwTEok97VWcjxIiSOjiykti.o/pQs.wPvMxQ6Fm7I6IoYN3CmLs66x9t0oSwbtEW7o7UmJEiDwGqd8p4ur1"},
+		{"we have a short salt string but not a short password", "short", 77777, true, "$6$rounds=77777$short$WuQyW2YR.hBNpjjRhpYD/ifIw05xdfeEyQoMxIXbkvr0gge1a1x3yRULJ5CCaUeOxFmtlcGZelFl5CxtgfiAc0"},
+		{"a short string", "asaltof16chars..", 123456, true, "$6$rounds=123456$asaltof16chars..$BtCwjqMJGx5hrJhZywWvt0RLE8uZ4oPwcelCjmw2kSYu.Ec6ycULevoBK25fs2xXgMNrCzIMVcgEJAstJeonj1"},
+		{"the minimum number is still observed", "roundstoolow", 1000, true, "$6$rounds=1000$roundstoolow$kUMsbe306n21p9R.FRkW3IGn.S9NPN0x50YhH1xhLsPuWGsUSklZt58jaTfF4ZEQpyUNGc0dqbpBYYBaHHrsX."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.salt, func(t *testing.T) {
+			if got := sha512Crypt([]byte(tt.password), []byte(tt.salt), tt.rounds, tt.explicit); got != tt.want {
+				t.Errorf("sha512Crypt() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestVerifyPassword checks hashes created by other implementations: crypt(3) of libxcrypt,
+// the reference implementation of Argon2 and Python's hashlib.
+func TestVerifyPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		hash     string
+	}{
+		{"sha512-crypt", "Hello world!", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+		{"sha512-crypt with rounds", "a short string", "$6$rounds=123456$asaltof16chars..$BtCwjqMJGx5hrJhZywWvt0RLE8uZ4oPwcelCjmw2kSYu.Ec6ycULevoBK25fs2xXgMNrCzIMVcgEJAstJeonj1"},
+		// Too few rounds are raised to the minimum of 1000.
+		{"sha512-crypt with too few rounds", "the minimum number is still observed", "$6$rounds=10$roundstoolow$kUMsbe306n21p9R.FRkW3IGn.S9NPN0x50YhH1xhLsPuWGsUSklZt58jaTfF4ZEQpyUNGc0dqbpBYYBaHHrsX."},
+		{"argon2id", "password", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"scrypt", "correct horse", "$scrypt$ln=10,r=8,p=1$c2FsdHNhbHRzYWx0c2FsdA$A9lBa6RTbfBovWqamVIqXKovIl4Vk6OZyVojLJmYmSI"},
+		{"pbkdf2-sha256", "correct horse", "$pbkdf2-sha256$i=1000$c2FsdHNhbHRzYWx0c2FsdA$BBs+1+PaslLtBPULUr8/lQicvVuHiEPMz0i8MjLCbzM"},
+		{"pbkdf2-sha512", "correct horse", "$pbkdf2-sha512$i=1000$c2FsdHNhbHRzYWx0c2FsdA$EHLBej+uEvxlmr0QnHnceg1vM6h1wbwSP5XErh3SmizNDWZsIi28RPGARe0EssjGRoiACzxgHjNvqwJY1zxKig"},
+		{"bcrypt", "password", "$2y$05$bvIG6Nmid91Mu9RcmmWZfO5HJIMCT8riNW0hEp8f6/FuA2/mHZFpe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := VerifyPassword(tt.password, tt.hash)
+			if err != nil || !ok {
+				t.Errorf("VerifyPassword() = %v, %v, want true", ok, err)
+			}
+			ok, err = VerifyPassword(tt.password+"!", tt.hash)
+			if err != nil || ok {
+				t.Errorf("VerifyPassword() with a wrong password = %v, %v, want false", ok, err)
+			}
+		})
+	}
+}
+
+// TestHashPassword checks the format of new hashes and that they verify.
+func TestHashPassword(t *testing.T) {
+	tests := []struct {
+		config  HashConfig
+		pattern string
+	}{
+		// Low costs keep the test fast.
+		{HashConfig{Algorithm: HashBcrypt, Cost: 4}, `^\$2a\$04\$[./A-Za-z0-9]{53}$`},
+		{HashConfig{Algorithm: HashArgon2id, Rounds: 1, Memory: 1024, Parallelism: 2}, `^\$argon2id\$v=19\$m=1024,t=1,p=2\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`},
+		{HashConfig{Algorithm: HashScrypt, Cost: 10}, `^\$scrypt\$ln=10,r=8,p=1\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`},
+		{HashConfig{Algorithm: HashSHA512Crypt, Rounds: 1000}, `^\$6\$rounds=1000\$[./A-Za-z0-9]{16}\$[./A-Za-z0-9]{86}$`},
+		{HashConfig{Algorithm: HashPBKDF2, Rounds: 1000}, `^\$pbkdf2-sha256\$i=1000\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.config.Algorithm, func(t *testing.T) {
+			hashed, err := HashPassword("correct horse battery staple", tt.config)
+			if err != nil {
+				t.Fatalf("HashPassword() error = %v", err)
+			}
+			if !regexp.MustCompile(tt.pattern).MatchString(hashed) {
+				t.Errorf("HashPassword() = %s, want %s", hashed, tt.pattern)
+			}
+
+			// Hashes are salted, so the same password hashes differently.
+			again, err := HashPassword("correct horse battery staple", tt.config)
+			if err != nil {
+				t.Fatalf("HashPassword() error = %v", err)
+			}
+			if again == hashed {
+				t.Errorf("HashPassword() = %s twice, want different salts", hashed)
+			}
+
+			if ok, err := VerifyPassword("correct horse battery staple", hashed); err != nil || !ok {
+				t.Errorf("VerifyPassword() = %v, %v, want true", ok, err)
+			}
+		})
+	}
+}
+
+// TestHashPasswordDefaults checks the default costs, except for the slow scrypt and bcrypt.
+func TestHashPasswordDefaults(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		prefix    string
+	}{
+		{HashArgon2id, "$argon2id$v=19$m=65536,t=3,p=4$"},
+		{HashSHA512Crypt, "$6$rounds=656000$"},
+		{HashPBKDF2, "$pbkdf2-sha256$i=600000$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			hashed, err := HashPassword("secret", HashConfig{Algorithm: tt.algorithm})
+			if err != nil {
+				t.Fatalf("HashPassword() error = %v", err)
+			}
+			if !strings.HasPrefix(hashed, tt.prefix) {
+				t.Errorf("HashPassword() = %s, want prefix %s", hashed, tt.prefix)
+			}
+		})
+	}
+}
+
+// TestHashPasswordErrors checks that unsupported algorithms and costs are rejected.
+func TestHashPasswordErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  HashConfig
+		wantErr string
+	}{
+		{"Unknown algorithm", HashConfig{Algorithm: "md5-crypt"}, "unknown hash algorithm"},
+		{"bcrypt cost", HashConfig{Algorithm: HashBcrypt, Cost: 32}, "bcrypt cost"},
+		{"argon2id lanes", HashConfig{Algorithm: HashArgon2id, Parallelism: 256}, "lanes"},
+		{"argon2id memory", HashConfig{Algorithm: HashArgon2id, Memory: 16, Parallelism: 4}, "memory"},
+		{"argon2id maximum memory", HashConfig{Algorithm: HashArgon2id, Memory: HashMaxMemory + 1}, "memory"},
+		{"argon2id passes", HashConfig{Algorithm: HashArgon2id, Rounds: Argon2idMaxPasses + 1}, "passes"},
+		{"scrypt memory", HashConfig{Algorithm: HashScrypt, Cost: 23, Parallelism: 1}, "memory"},
+		{"scrypt cost", HashConfig{Algorithm: HashScrypt, Cost: 31}, "scrypt"},
+		{"sha512-crypt rounds", HashConfig{Algorithm: HashSHA512Crypt, Rounds: 999}, "rounds"},
+		{"sha512-crypt maximum rounds", HashConfig{Algorithm: HashSHA512Crypt, Rounds: SHA512CryptRoundsLimit + 1}, "rounds"},
+		{"scrypt work", HashConfig{Algorithm: HashScrypt, Cost: 17, Parallelism: 17}, "N * r * p"},
+		{"pbkdf2 iterations", HashConfig{Algorithm: HashPBKDF2, Rounds: -1}, "iteration"},
+		{"pbkdf2 maximum iterations", HashConfig{Algorithm: HashPBKDF2, Rounds: PBKDF2MaxIterations + 1}, "iteration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := HashPassword("secret", tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("HashPassword() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// bcrypt only hashes the first 72 bytes, so longer passwords are rejected.
+	if _, err := HashPassword(strings.Repeat("x", 73), HashConfig{Algorithm: HashBcrypt, Cost: 4}); err == nil {
+		t.Errorf("HashPassword() of 73 bytes with bcrypt succeeded")
+	}
+}
+
+// TestVerifyPasswordErrors checks that unsupported and malformed hashes are rejected.
+func TestVerifyPasswordErrors(t *testing.T) {
+	for _, hashed := range []string{
+		"",
+		"plaintext",
+		"$1$saltsalt$2vnaRpHa6Jxjz5n83ok8Z0",
+		"$6$rounds=many$salt$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+		"$6$saltstring$short",
+		"$argon2id$v=16$m=65536,t=2,p=4$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$t=2,m=65536,p=4$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$scrypt$ln=10,r=8,p=1$c2FsdHNhbHRzYWx0c2FsdA$",
+		// Costs which would crash or exhaust the machine.
+		"$scrypt$ln=4,r=8,p=0$c2FsdA$a2V5",
+		"$scrypt$ln=4,r=0,p=1$c2FsdA$a2V5",
+		"$scrypt$ln=4,r=1024,p=1048576$c2FsdA$a2V5",
+		"$scrypt$ln=4,r=16,p=1152921504606846976$c2FsdA$a2V5",
+		"$scrypt$ln=30,r=8,p=1$c2FsdA$a2V5",
+		"$scrypt$ln=1,r=1,p=1000000000$c2FsdA$a2V5",
+		"$scrypt$ln=17,r=8,p=17$c2FsdA$a2V5",
+		"$6$rounds=999999999$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+		"$argon2id$v=19$m=4294967295,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=65536,t=2000000000,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=16,t=2,p=4$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$pbkdf2-sha256$i=2000000000$c2FsdHNhbHRzYWx0c2FsdA$BBs+1+PaslLtBPULUr8/lQicvVuHiEPMz0i8MjLCbzM",
+		"$pbkdf2-md5$i=1000$c2FsdHNhbHRzYWx0c2FsdA$BBs+1+PaslLtBPULUr8/lQicvVuHiEPMz0i8MjLCbzM",
+		"$2b$04$invalid",
+	} {
+		if ok, err := VerifyPassword("password", hashed); err == nil || ok {
+			t.Errorf("VerifyPassword(%q) = %v, %v, want an error", hashed, ok, err)
+		}
+	}
+}
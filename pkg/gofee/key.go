@@ -0,0 +1,289 @@
+package gofee
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Types of keys.
+const (
+	KeyAES128     = "aes128"
+	KeyAES256     = "aes256"
+	KeyHMACSHA256 = "hmac-sha256"
+	KeyHMACSHA512 = "hmac-sha512"
+	KeyChaCha20   = "chacha20"
+	KeyEd25519    = "ed25519"
+	KeyX25519     = "x25519"
+	KeyP256       = "p256"
+	KeyRSA        = "rsa"
+)
+
+// KeyTypes lists the key types in the order they are documented.
+var KeyTypes = []string{KeyAES128, KeyAES256, KeyHMACSHA256, KeyHMACSHA512, KeyChaCha20, KeyEd25519, KeyX25519, KeyP256, KeyRSA}
+
+// Formats of keys.
+const (
+	KeyFormatPEM     = "pem"     // PKCS #8 private keys and PKIX public keys.
+	KeyFormatJWK     = "jwk"     // JSON Web Keys of RFC 7517.
+	KeyFormatOpenSSH = "openssh" // OpenSSH private keys and authorized_keys lines.
+	KeyFormatHex     = "hex"     // Hexadecimal symmetric keys.
+	KeyFormatBase64  = "base64"  // Base64 symmetric keys.
+)
+
+// KeyFormats lists the key formats in the order they are documented.
+var KeyFormats = []string{KeyFormatPEM, KeyFormatJWK, KeyFormatOpenSSH, KeyFormatHex, KeyFormatBase64}
+
+// Sizes of RSA keys in bits.
+const (
+	RSABits    = 3072 // The default, which is as strong as a 128 bit symmetric key.
+	RSAMinBits = 2048
+	RSAMaxBits = 8192
+)
+
+// symmetricKeySizes maps the types of symmetric keys to their sizes in bytes. HMAC keys have
+// the block size of their hash, the longest size without hashing the key first.
+var symmetricKeySizes = map[string]int{
+	KeyAES128:     16,
+	KeyAES256:     32,
+	KeyHMACSHA256: 64,
+	KeyHMACSHA512: 128,
+	KeyChaCha20:   32,
+}
+
+// jwkAlgorithms maps the types of symmetric keys to the algorithms of their JSON Web Keys.
+var jwkAlgorithms = map[string]string{
+	KeyAES128:     "A128GCM",
+	KeyAES256:     "A256GCM",
+	KeyHMACSHA256: "HS256",
+	KeyHMACSHA512: "HS512",
+}
+
+// KeyConfig describes a key.
+type KeyConfig struct {
+	Type string // One of KeyTypes.
+	Bits int    // The size of RSA keys, RSABits if 0.
+}
+
+// Key is a symmetric key or the private key of a key pair.
+type Key struct {
+	Type    string
+	Secret  []byte            // The symmetric key, nil for key pairs.
+	Private crypto.PrivateKey // The private key of a key pair, nil for symmetric keys.
+}
+
+// GenerateKey creates a random key using the default Generator.
+func GenerateKey(config KeyConfig) (*Key, error) {
+	return defaultGenerator.GenerateKey(config)
+}
+
+// GenerateKey creates a random symmetric key or key pair.
+func (g *Generator) GenerateKey(config KeyConfig) (*Key, error) {
+	if size, ok := symmetricKeySizes[config.Type]; ok {
+		secret, err := g.randomBytes(size)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{Type: config.Type, Secret: secret}, nil
+	}
+
+	var private crypto.PrivateKey
+	var err error
+	switch config.Type {
+	case KeyEd25519:
+		_, private, err = ed25519.GenerateKey(g.reader())
+	case KeyX25519:
+		private, err = ecdh.X25519().GenerateKey(g.reader())
+	case KeyP256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), g.reader())
+	case KeyRSA:
+		bits := config.Bits
+		if bits == 0 {
+			bits = RSABits
+		}
+		if bits < RSAMinBits || bits > RSAMaxBits {
+			return nil, fmt.Errorf("RSA keys must have %d to %d bits", RSAMinBits, RSAMaxBits)
+		}
+		private, err = rsa.GenerateKey(g.reader(), bits)
+	default:
+		return nil, fmt.Errorf("unknown key type %q (supported: %s)", config.Type, strings.Join(KeyTypes, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error generating key: %v", err)
+	}
+	return &Key{Type: config.Type, Private: private}, nil
+}
+
+// Bits returns the size of the key in bits: the size of symmetric and RSA keys, or the size of
+// the curve of elliptic curve keys.
+func (k *Key) Bits() int {
+	switch private := k.Private.(type) {
+	case *rsa.PrivateKey:
+		return private.N.BitLen()
+	case nil:
+		return 8 * len(k.Secret)
+	}
+	return 256
+}
+
+// Public returns the public key of a key pair, or nil for symmetric keys.
+func (k *Key) Public() crypto.PublicKey {
+	switch private := k.Private.(type) {
+	case ed25519.PrivateKey:
+		return private.Public()
+	case *ecdh.PrivateKey:
+		return private.PublicKey()
+	case *ecdsa.PrivateKey:
+		return &private.PublicKey
+	case *rsa.PrivateKey:
+		return &private.PublicKey
+	}
+	return nil
+}
+
+// Encode returns the private or symmetric key and the public key in the format. The public
+// key is empty for symmetric keys. The comment is added to OpenSSH keys.
+func (k *Key) Encode(format, comment string) (private, public string, err error) {
+	switch format {
+	case KeyFormatHex, KeyFormatBase64:
+		if k.Secret == nil {
+			return "", "", fmt.Errorf("%s only supports symmetric keys, use %s, %s or %s", format, KeyFormatPEM, KeyFormatJWK, KeyFormatOpenSSH)
+		}
+		if format == KeyFormatHex {
+			return hex.EncodeToString(k.Secret) + "\n", "", nil
+		}
+		return base64.StdEncoding.EncodeToString(k.Secret) + "\n", "", nil
+	case KeyFormatJWK:
+		return k.encodeJWK()
+	case KeyFormatPEM, KeyFormatOpenSSH:
+		if k.Secret != nil {
+			return "", "", fmt.Errorf("%s does not support symmetric keys, use %s, %s or %s", format, KeyFormatJWK, KeyFormatHex, KeyFormatBase64)
+		}
+		if format == KeyFormatPEM {
+			return k.encodePEM()
+		}
+		return k.encodeOpenSSH(comment)
+	}
+	return "", "", fmt.Errorf("unknown key format %q (supported: %s)", format, strings.Join(KeyFormats, ", "))
+}
+
+// encodePEM encodes a key pair as a PKCS #8 private key and a PKIX public key.
+func (k *Key) encodePEM() (string, string, error) {
+	private, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return "", "", err
+	}
+	public, err := x509.MarshalPKIXPublicKey(k.Public())
+	if err != nil {
+		return "", "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: private})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public})), nil
+}
+
+// encodeOpenSSH encodes a key pair as an OpenSSH private key and an authorized_keys line.
+func (k *Key) encodeOpenSSH(comment string) (string, string, error) {
+	if k.Type == KeyX25519 {
+		return "", "", fmt.Errorf("%s keys cannot be used with OpenSSH", k.Type)
+	}
+	block, err := ssh.MarshalPrivateKey(k.Private, comment)
+	if err != nil {
+		return "", "", err
+	}
+	public, err := ssh.NewPublicKey(k.Public())
+	if err != nil {
+		return "", "", err
+	}
+
+	line := bytes.TrimSuffix(ssh.MarshalAuthorizedKey(public), []byte("\n"))
+	if comment != "" {
+		line = append(append(line, ' '), comment...)
+	}
+	return string(pem.EncodeToMemory(block)), string(line) + "\n", nil
+}
+
+// jwk is a JSON Web Key. The fields are in the order of RFC 7518.
+type jwk struct {
+	KeyType string `json:"kty"`
+	Curve   string `json:"crv,omitempty"`
+	Alg     string `json:"alg,omitempty"`
+	K       string `json:"k,omitempty"`
+	N       string `json:"n,omitempty"`
+	E       string `json:"e,omitempty"`
+	X       string `json:"x,omitempty"`
+	Y       string `json:"y,omitempty"`
+	D       string `json:"d,omitempty"`
+	P       string `json:"p,omitempty"`
+	Q       string `json:"q,omitempty"`
+	DP      string `json:"dp,omitempty"`
+	DQ      string `json:"dq,omitempty"`
+	QI      string `json:"qi,omitempty"`
+}
+
+// encodeJWK encodes a key as a private and a public JSON Web Key.
+func (k *Key) encodeJWK() (string, string, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	b64Int := func(n *big.Int) string { return b64(n.Bytes()) }
+
+	var private jwk
+	switch key := k.Private.(type) {
+	case nil:
+		private = jwk{KeyType: "oct", Alg: jwkAlgorithms[k.Type], K: b64(k.Secret)}
+	case ed25519.PrivateKey:
+		private = jwk{KeyType: "OKP", Curve: "Ed25519", X: b64(key.Public().(ed25519.PublicKey)), D: b64(key.Seed())}
+	case *ecdh.PrivateKey:
+		private = jwk{KeyType: "OKP", Curve: "X25519", X: b64(key.PublicKey().Bytes()), D: b64(key.Bytes())}
+	case *ecdsa.PrivateKey:
+		ecdhKey, err := key.ECDH()
+		if err != nil {
+			return "", "", err
+		}
+		// The uncompressed point is 0x04 followed by the coordinates of 32 bytes each.
+		point := ecdhKey.PublicKey().Bytes()
+		private = jwk{KeyType: "EC", Curve: "P-256", X: b64(point[1:33]), Y: b64(point[33:]), D: b64(ecdhKey.Bytes())}
+	case *rsa.PrivateKey:
+		private = jwk{
+			KeyType: "RSA",
+			N:       b64Int(key.N),
+			E:       b64Int(big.NewInt(int64(key.E))),
+			D:       b64Int(key.D),
+			P:       b64Int(key.Primes[0]),
+			Q:       b64Int(key.Primes[1]),
+			DP:      b64Int(key.Precomputed.Dp),
+			DQ:      b64Int(key.Precomputed.Dq),
+			QI:      b64Int(key.Precomputed.Qinv),
+		}
+	default:
+		return "", "", fmt.Errorf("unsupported key %T", key)
+	}
+
+	privateJSON, err := json.MarshalIndent(private, "", "  ")
+	if err != nil {
+		return "", "", err
+	}
+	if k.Secret != nil {
+		return string(privateJSON) + "\n", "", nil
+	}
+
+	// The public key is the private key without its private parameters.
+	public := jwk{KeyType: private.KeyType, Curve: private.Curve, N: private.N, E: private.E, X: private.X, Y: private.Y}
+	publicJSON, err := json.MarshalIndent(public, "", "  ")
+	if err != nil {
+		return "", "", err
+	}
+	return string(privateJSON) + "\n", string(publicJSON) + "\n", nil
+}
@@ -0,0 +1,109 @@
+package gofee
+
+import "strings"
+
+// Keyboard layouts used to detect keyboard walks such as "qwerty" or "7896".
+// Every key is written as its unshifted character followed by its shifted one.
+// The rows of the qwerty layout are slanted, each one indented by one more space.
+const (
+	qwertyLayout = `
+` + "`~" + ` 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+
+    qQ wW eE rR tT yY uU iI oO pP [{ ]} \|
+     aA sS dD fF gG hH jJ kK lL ;: '"
+      zZ xX cC vV bB nN mM ,< .> /?
+`
+
+	keypadLayout = `
+  / * -
+7 8 9 +
+4 5 6
+1 2 3
+  0 .
+`
+)
+
+// keyboard is the adjacency graph of a keyboard layout.
+type keyboard struct {
+	name string
+	// adjacent maps every character to the keys surrounding it, in a fixed order of
+	// directions. Missing neighbours are empty strings, so the index of a neighbour
+	// identifies the direction of a step.
+	adjacent map[rune][]string
+	// startingPositions is the number of characters on the keyboard.
+	startingPositions int
+	// averageDegree is the average number of neighbours of a key.
+	averageDegree float64
+}
+
+// keyboards are the adjacency graphs of the supported keyboard layouts.
+var keyboards = []*keyboard{
+	newKeyboard("qwerty", qwertyLayout, true),
+	newKeyboard("keypad", keypadLayout, false),
+}
+
+// newKeyboard builds the adjacency graph of a keyboard layout.
+// Slanted layouts have six neighbours per key, aligned layouts like keypads have eight.
+func newKeyboard(name, layout string, slanted bool) *keyboard {
+	type position struct{ x, y int }
+
+	keys := make(map[position]string)
+	lines := strings.Split(layout, "\n")
+	unit := len(strings.Fields(layout)[0]) + 1
+
+	// Find the position of every key, taking the indentation of slanted rows into account.
+	for y, line := range lines {
+		slant := 0
+		if slanted {
+			slant = y - 1
+		}
+		for x := 0; x < len(line); x++ {
+			if line[x] == ' ' {
+				continue
+			}
+			end := strings.IndexByte(line[x:], ' ')
+			if end < 0 {
+				end = len(line) - x
+			}
+			keys[position{(x - slant) / unit, y}] = line[x : x+end]
+			x += end
+		}
+	}
+
+	neighbours := func(p position) []position {
+		x, y := p.x, p.y
+		if slanted {
+			return []position{{x - 1, y}, {x, y - 1}, {x + 1, y - 1}, {x + 1, y}, {x, y + 1}, {x - 1, y + 1}}
+		}
+		return []position{{x - 1, y}, {x - 1, y - 1}, {x, y - 1}, {x + 1, y - 1}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}, {x - 1, y + 1}}
+	}
+
+	k := &keyboard{name: name, adjacent: make(map[rune][]string)}
+	var degrees int
+	for p, key := range keys {
+		var adjacent []string
+		for _, n := range neighbours(p) {
+			adjacent = append(adjacent, keys[n])
+			if keys[n] != "" {
+				degrees++
+			}
+		}
+		for _, c := range key {
+			k.adjacent[c] = adjacent
+		}
+	}
+
+	k.startingPositions = len(k.adjacent)
+	k.averageDegree = float64(degrees) / float64(len(keys))
+	return k
+}
+
+// step returns the direction of the step from the key of a to the key of b and whether b
+// is the shifted character of its key. The direction is -1 if the keys are not adjacent.
+func (k *keyboard) step(a, b rune) (direction int, shifted bool) {
+	for i, key := range k.adjacent[a] {
+		if idx := strings.IndexRune(key, b); idx >= 0 {
+			return i, idx > 0
+		}
+	}
+	return -1, false
+}
@@ -0,0 +1,64 @@
+package gofee
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestNewKeyboard checks the adjacency graphs of the keyboard layouts.
+func TestNewKeyboard(t *testing.T) {
+	qwerty, keypad := keyboards[0], keyboards[1]
+
+	tests := []struct {
+		name     string
+		keyboard *keyboard
+		char     rune
+		want     []string
+	}{
+		{
+			name:     "Qwerty letter",
+			keyboard: qwerty,
+			char:     'g',
+			want:     []string{"fF", "tT", "yY", "hH", "bB", "vV"},
+		},
+		{
+			name:     "Qwerty edge",
+			keyboard: qwerty,
+			char:     '!',
+			want:     []string{"`~", "", "", "2@", "qQ", ""},
+		},
+		{
+			name:     "Keypad digit",
+			keyboard: keypad,
+			char:     '5',
+			want:     []string{"4", "7", "8", "9", "6", "3", "2", "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.keyboard.adjacent[tt.char]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("adjacent[%q] = %q, want %q", tt.char, got, tt.want)
+			}
+		})
+	}
+
+	if qwerty.startingPositions != 94 || keypad.startingPositions != 15 {
+		t.Errorf("starting positions = %d and %d, want 94 and 15", qwerty.startingPositions, keypad.startingPositions)
+	}
+}
+
+// TestKeyboardStep tests the directions of steps between keys.
+func TestKeyboardStep(t *testing.T) {
+	qwerty := keyboards[0]
+
+	if direction, shifted := qwerty.step('q', 'w'); direction != 3 || shifted {
+		t.Errorf("step('q', 'w') = %d, %v, want 3, false", direction, shifted)
+	}
+	if direction, shifted := qwerty.step('q', 'W'); direction != 3 || !shifted {
+		t.Errorf("step('q', 'W') = %d, %v, want 3, true", direction, shifted)
+	}
+	if direction, _ := qwerty.step('q', 'p'); direction != -1 {
+		t.Errorf("step('q', 'p') = %d, want -1", direction)
+	}
+}
@@ -1,46 +1,173 @@
 package gofee
 
 import (
-	"crypto/rand"
 	"fmt"
-	"math/big"
+	"io"
+	"math"
+	"math/bits"
+	"reflect"
+	"unicode/utf8"
 )
 
-// Charset is a global variable that holds the set of characters used to generate passwords.
-// It is built based on the provided PasswordConfig.
-var Charset string
+// randomBufferSize is the number of random bytes read from the source at once.
+const randomBufferSize = 512
 
-// MapToCharset generates a random password of the given length using the configured Charset.
-// It returns the generated password or an error if Charset is empty or random number generation fails.
+// CharsetGenerator generates passwords whose characters are drawn uniformly from a charset.
+type CharsetGenerator struct {
+	g       *Generator
+	length  int
+	charset string
+}
+
+// Generate returns a new random password drawn from the charset.
+func (c *CharsetGenerator) Generate() (string, error) {
+	password, err := c.g.mapToCharset(c.length, c.charset)
+	if err != nil {
+		// Return an error if mapToCharset fails, including the specific error message.
+		return "", fmt.Errorf("error mapping number to charset: %v", err)
+	}
+	return password, nil
+}
+
+// Entropy returns length * log2(charset size) bits, where the size is the number of runes.
+func (c *CharsetGenerator) Entropy() float64 {
+	return float64(c.length) * math.Log2(float64(utf8.RuneCountInString(c.charset)))
+}
+
+// Charset returns the characters the passwords are drawn from.
+func (c *CharsetGenerator) Charset() string {
+	return c.charset
+}
+
+// PinGenerator generates numeric PINs. It is a CharsetGenerator of the
+// digits that are left after removing the excluded characters.
+type PinGenerator struct {
+	CharsetGenerator
+}
+
+// MapToCharset generates a random password of the given length using the default Generator,
+// which reads its randomness from crypto/rand.Reader.
 func MapToCharset(length int, config PasswordConfig) (string, error) {
+	return defaultGenerator.MapToCharset(length, config)
+}
+
+// MapToCharset generates a random password of the given length using the charset built from the config.
+// It returns the generated password or an error if the charset is empty or random number generation fails.
+func (g *Generator) MapToCharset(length int, config PasswordConfig) (string, error) {
+	// Build the charset based on the provided configuration.
+	return g.mapToCharset(length, BuildCharset(config))
+}
+
+// mapToCharset generates a random password of the given length using the characters of charset.
+// The length is the number of runes, and every rune of the charset is a character, so charsets
+// may contain any Unicode characters.
+func (g *Generator) mapToCharset(length int, charset string) (string, error) {
 	// Return an error if the length is invalid.
 	if length <= 0 {
 		return "", fmt.Errorf("length must be greater than 0")
 	}
 
-	// Build the Charset based on the provided configuration.
-	Charset = BuildCharset(config)
-	charsetLen := int64(len(Charset))
+	runes := []rune(charset)
+	charsetLen := len(runes)
 
-	// Return an error if no characters are available in the Charset.
+	// Return an error if no characters are available in the charset.
 	if charsetLen == 0 {
 		return "", fmt.Errorf("charset is empty")
 	}
 
 	// Allocate space for the generated password.
-	ret := make([]byte, length)
+	ret := make([]rune, length)
 
-	// Generate 'l' random characters from the Charset.
+	// Generate 'l' random characters from the charset.
 	for i := 0; i < length; i++ {
 		// Generate a random number in the range [0, charsetLen).
-		num, err := rand.Int(rand.Reader, big.NewInt(charsetLen))
+		num, err := g.randomIndex(charsetLen)
 		if err != nil {
-			return "", fmt.Errorf("error generating random number: %v", err)
+			return "", err
 		}
 		// Assign the corresponding character to the password.
-		ret[i] = Charset[num.Int64()]
+		ret[i] = runes[num]
 	}
 
-	// Convert the byte slice to a string and return the generated password.
+	// Convert the rune slice to a string and return the generated password.
 	return string(ret), nil
 }
+
+// randomIndex returns a uniformly distributed random number in the range [0, n).
+//
+// It uses rejection sampling like crypto/rand.Int, without allocating: it reads the smallest
+// number of bytes holding n-1, masks off the bits above its bit length and draws again if the
+// value is n or greater. Every value in range is equally likely, and as the mask keeps the
+// range below 2n, fewer than two draws are needed on average.
+func (g *Generator) randomIndex(n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("error generating random number: invalid range %d", n)
+	}
+
+	bitLen := bits.Len64(uint64(n - 1))
+	if bitLen == 0 {
+		return 0, nil
+	}
+	size := (bitLen + 7) / 8
+	mask := uint64(1)<<bitLen - 1
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var b [8]byte
+	for {
+		if err := g.readRandom(b[:size]); err != nil {
+			return 0, fmt.Errorf("error generating random number: %v", err)
+		}
+
+		var v uint64
+		for _, c := range b[:size] {
+			v = v<<8 | uint64(c)
+		}
+		if v &= mask; v < uint64(n) {
+			return int(v), nil
+		}
+	}
+}
+
+// readRandom fills p with random bytes, which are read from the source of the Generator in blocks
+// of randomBufferSize bytes. Bytes buffered from a previous source are discarded, so replacing
+// crypto/rand.Reader still takes effect immediately. The caller must hold g.mu.
+func (g *Generator) readRandom(p []byte) error {
+	r := g.reader()
+	if !sameReader(r, g.source) {
+		g.source = r
+		g.buffered = nil
+	}
+
+	for len(p) > 0 {
+		if len(g.buffered) == 0 {
+			if g.buf == nil {
+				g.buf = make([]byte, randomBufferSize)
+			}
+			// Short reads are fine, only reading nothing is an error.
+			n, err := r.Read(g.buf)
+			if n == 0 {
+				if err == nil {
+					err = io.ErrNoProgress
+				}
+				return err
+			}
+			g.buffered = g.buf[:n]
+		}
+
+		n := copy(p, g.buffered)
+		g.buffered = g.buffered[n:]
+		p = p[n:]
+	}
+	return nil
+}
+
+// sameReader reports whether a and b are the same reader. Readers of types which cannot be
+// compared are never the same.
+func sameReader(a, b io.Reader) bool {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
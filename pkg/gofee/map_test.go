@@ -1,9 +1,17 @@
 package gofee
 
 import (
+	"bytes"
 	"crypto/rand"
 	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // TestMapToCharset runs a series of tests for the MapToCharset function.
@@ -120,7 +128,7 @@ func TestMapToCharset(t *testing.T) {
 			expectedSet: Digits, // Expected charset is only digits.
 		},
 		{
-			name: "Valid charset with type memorable",
+			name: "Empty charset with type memorable",
 			args: args{
 				length: 16,
 				config: PasswordConfig{
@@ -131,9 +139,9 @@ func TestMapToCharset(t *testing.T) {
 					Type:           "memorable",
 				},
 			},
-			wantErr:     false,
-			expectedLen: 16,
-			expectedSet: Lowers + Uppers, // Expected charset includes lowercase and uppercase.
+			wantErr:     true,
+			expectedLen: 0,
+			expectedSet: "", // Memorable passwords are passphrases and have no charset.
 		},
 	}
 
@@ -199,3 +207,254 @@ func TestMapToCharset_RandIntError(t *testing.T) {
 		t.Errorf("MapToCharset() error = %v, wanted error: %v", err, "mocked error from rand.Reader")
 	}
 }
+
+// TestGenerateUnicode checks that passwords of Unicode charsets are valid UTF-8 in normalization
+// form C, have the requested number of runes and only consist of characters of the charset.
+func TestGenerateUnicode(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		config  PasswordConfig
+		charset string // The characters the passwords may consist of.
+		runes   int    // The number of runes of the passwords.
+	}{
+		{
+			name:    "Accented letters",
+			length:  12,
+			config:  PasswordConfig{CustomCharset: "àéîõüçñ"},
+			charset: "àéîõüçñ",
+			runes:   12,
+		},
+		{
+			name:    "Cyrillic",
+			length:  20,
+			config:  PasswordConfig{CustomCharset: "абвгдежзийклмнопрстуфхцчшщъыьэюя"},
+			charset: "абвгдежзийклмнопрстуфхцчшщъыьэюя",
+			runes:   20,
+		},
+		{
+			name:    "Emoji",
+			length:  8,
+			config:  PasswordConfig{CustomCharset: "😀🎉🔑🐙🚀"},
+			charset: "😀🎉🔑🐙🚀",
+			runes:   8,
+		},
+		{
+			name:    "Decomposed charset",
+			length:  10,
+			config:  PasswordConfig{CustomCharset: "éö"},
+			charset: "éö",
+			runes:   10,
+		},
+		{
+			name:    "Unicode symbols with constraints",
+			length:  16,
+			config:  PasswordConfig{IncludeLowers: true, IncludeSymbols: true, CustomSymbols: "§¶€£", MinSymbols: 4},
+			charset: Lowers + "§¶€£",
+			runes:   16,
+		},
+		{
+			name:    "First characters",
+			length:  10,
+			config:  PasswordConfig{CustomCharset: "ÄÖÜäöü", FirstChars: "ÄÖÜ"},
+			charset: "ÄÖÜäöü",
+			runes:   10,
+		},
+		{
+			name:    "Pattern",
+			length:  1,
+			config:  PasswordConfig{Pattern: "s{6}-ñ", CustomSymbols: "€£¥"},
+			charset: "€£¥-ñ",
+			runes:   8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				result, err := GenerateResult(tt.length, tt.config)
+				if err != nil {
+					t.Fatalf("GenerateResult() error = %v", err)
+				}
+
+				password := result.Password
+				if !utf8.ValidString(password) || !norm.NFC.IsNormalString(password) {
+					t.Fatalf("GenerateResult() = %q, which is not valid UTF-8 in NFC", password)
+				}
+				if n := utf8.RuneCountInString(password); n != tt.runes {
+					t.Errorf("GenerateResult() = %q, want %d runes, got %d", password, tt.runes, n)
+				}
+				for _, c := range password {
+					if !strings.ContainsRune(tt.charset, c) {
+						t.Errorf("GenerateResult() = %q, %q not in %q", password, c, tt.charset)
+					}
+				}
+			}
+		})
+	}
+
+	// The entropy is based on the number of characters, not bytes.
+	result, err := GenerateResult(10, PasswordConfig{CustomCharset: "😀🎉🔑🐙"})
+	if err != nil {
+		t.Fatalf("GenerateResult() error = %v", err)
+	}
+	if result.Entropy != 20 {
+		t.Errorf("GenerateResult() entropy = %v, want 20", result.Entropy)
+	}
+}
+
+// TestRandomIndexRejection checks that values outside the range are drawn again.
+func TestRandomIndexRejection(t *testing.T) {
+	// For n = 10 the values are masked to 4 bits, so 0xff (15) and 0x0c (12) are rejected.
+	g := NewGenerator(bytes.NewReader([]byte{0xff, 0x0c, 0x13, 0x09}))
+
+	for _, want := range []int{3, 9} {
+		got, err := g.randomIndex(10)
+		if err != nil {
+			t.Fatalf("randomIndex() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("randomIndex() = %d, want %d", got, want)
+		}
+	}
+
+	// The reader is exhausted.
+	if _, err := g.randomIndex(10); err == nil {
+		t.Errorf("randomIndex() returned no error after the end of the reader")
+	}
+	if _, err := g.randomIndex(0); err == nil {
+		t.Errorf("randomIndex(0) returned no error")
+	}
+}
+
+// TestRandomIndexMatchesRandInt checks that randomIndex draws the same numbers as crypto/rand.Int
+// from the same random bytes, so buffering does not change the sampling.
+func TestRandomIndexMatchesRandInt(t *testing.T) {
+	for _, n := range []int{1, 2, 10, 94, 256, 257, 7776, 1<<20 + 3, 1<<40 + 1} {
+		g := NewGenerator(&drbg{seed: []byte("index")})
+		reference := &drbg{seed: []byte("index")}
+
+		for i := 0; i < 1000; i++ {
+			got, err := g.randomIndex(n)
+			if err != nil {
+				t.Fatalf("randomIndex(%d) error = %v", n, err)
+			}
+			want, err := rand.Int(reference, big.NewInt(int64(n)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(got) != want.Int64() {
+				t.Fatalf("randomIndex(%d) draw %d = %d, want %d", n, i, got, want)
+			}
+		}
+	}
+}
+
+// TestRandomIndexUniformity checks with a chi-squared test that every number is equally likely,
+// including ranges which reject most of the drawn values.
+func TestRandomIndexUniformity(t *testing.T) {
+	for _, n := range []int{2, 10, 62, 94, 129, 1000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			samples := 500 * n
+			counts := make([]int, n)
+			for i := 0; i < samples; i++ {
+				num, err := defaultGenerator.randomIndex(n)
+				if err != nil {
+					t.Fatalf("randomIndex() error = %v", err)
+				}
+				counts[num]++
+			}
+
+			if stat, critical := chiSquared(counts, samples), chiSquaredCritical(n-1); stat > critical {
+				t.Errorf("chi-squared = %.2f, want at most %.2f for a uniform distribution", stat, critical)
+			}
+		})
+	}
+}
+
+// TestRandomIndexConcurrent checks that a Generator can be shared by goroutines.
+func TestRandomIndexConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if num, err := defaultGenerator.randomIndex(94); err != nil || num < 0 || num >= 94 {
+					t.Errorf("randomIndex() = %d, %v", num, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// randomIndexBigInt is the previous implementation of randomIndex, which allocates a big.Int and
+// reads from the source for every number. It is kept as the baseline of the benchmarks.
+func randomIndexBigInt(n int) (int, error) {
+	num, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(num.Int64()), nil
+}
+
+// BenchmarkRandomIndex compares the buffered sampling with crypto/rand.Int.
+func BenchmarkRandomIndex(b *testing.B) {
+	b.Run("buffered", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := defaultGenerator.randomIndex(94); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("big.Int", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := randomIndexBigInt(94); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkMapToCharset measures the throughput of generating passwords of different lengths.
+func BenchmarkMapToCharset(b *testing.B) {
+	for _, length := range []int{16, 64, 1024} {
+		b.Run(strconv.Itoa(length), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(length))
+			for i := 0; i < b.N; i++ {
+				if _, err := defaultGenerator.mapToCharset(length, All); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkMapToCharsetBigInt is the baseline of BenchmarkMapToCharset, drawing every character
+// with crypto/rand.Int.
+func BenchmarkMapToCharsetBigInt(b *testing.B) {
+	charset := []rune(All)
+
+	for _, length := range []int{16, 64, 1024} {
+		b.Run(strconv.Itoa(length), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(length))
+			for i := 0; i < b.N; i++ {
+				ret := make([]rune, length)
+				for j := range ret {
+					num, err := randomIndexBigInt(len(charset))
+					if err != nil {
+						b.Fatal(err)
+					}
+					ret[j] = charset[num]
+				}
+				_ = string(ret)
+			}
+		})
+	}
+}
@@ -0,0 +1,640 @@
+package gofee
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Patterns of the matches found in a password.
+const (
+	PatternDictionary = "dictionary"
+	PatternSpatial    = "spatial"
+	PatternRepeat     = "repeat"
+	PatternSequence   = "sequence"
+	PatternYear       = "year"
+	PatternDate       = "date"
+	PatternBruteforce = "bruteforce"
+)
+
+// Match is a part of a password that follows a guessable pattern.
+// Only the fields belonging to the Pattern of the match are set.
+type Match struct {
+	Pattern string  // The pattern of the match, one of the Pattern constants.
+	I, J    int     // The positions of the first and last rune of the match in the password.
+	Token   string  // The matched part of the password.
+	Guesses float64 // The estimated number of guesses needed to find the token.
+
+	// Dictionary matches.
+	Dictionary  string        // The name of the dictionary containing the word.
+	MatchedWord string        // The word of the dictionary.
+	Rank        int           // The frequency rank of the word, starting at 1.
+	Reversed    bool          // The token is the reversed word.
+	L33t        bool          // The token is the word with l33t substitutions.
+	Sub         map[rune]rune // The l33t substitutions, mapping the substitute to the letter.
+
+	// Spatial matches.
+	Keyboard string // The name of the keyboard layout.
+	Turns    int    // The number of changes of direction.
+	Shifted  int    // The number of shifted characters.
+
+	// Repeat matches.
+	BaseToken   string  // The repeated part of the token.
+	RepeatCount int     // The number of repetitions of the base token.
+	BaseGuesses float64 // The estimated number of guesses needed to find the base token.
+
+	// Sequence matches.
+	SequenceName string // The kind of the sequence, such as "lower" or "digits".
+	Ascending    bool   // The sequence is ascending.
+
+	// Date and year matches.
+	Year      int
+	Month     int
+	Day       int
+	Separator string
+}
+
+// l33tTable maps letters to the characters commonly substituted for them.
+var l33tTable = map[rune]string{
+	'a': "4@",
+	'b': "8",
+	'c': "({[<",
+	'e': "3",
+	'g': "69",
+	'i': "1!|",
+	'l': "1|7",
+	'o': "0",
+	's': "$5",
+	't': "+7",
+	'x': "%",
+	'z': "2",
+}
+
+const (
+	// maxL33tSubs limits the number of l33t substitution combinations tried per password.
+	maxL33tSubs = 64
+	// maxSequenceDelta is the largest step between the characters of a sequence.
+	maxSequenceDelta = 5
+	// Dates are only recognized between these years.
+	dateMinYear = 1000
+	dateMaxYear = 2050
+)
+
+// referenceYear is the year recent years and dates are compared with.
+var referenceYear = time.Now().Year()
+
+// recentYear matches four digit years of the 20th and 21st century.
+var recentYear = regexp.MustCompile(`19\d\d|20\d\d`)
+
+// dateWithSeparator matches dates such as 13.04.1987 or 2001-9-11.
+// Both separators of a date must be the same, which is checked separately.
+var dateWithSeparator = regexp.MustCompile(`^(\d{1,4})([\s/\\_.-])(\d{1,2})([\s/\\_.-])(\d{1,4})$`)
+
+// dateSplits lists the positions to split digit-only dates of a given length into three parts.
+var dateSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},
+	5: {{1, 3}, {2, 3}},
+	6: {{1, 2}, {2, 4}, {4, 5}},
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}},
+}
+
+// matcher finds all guessable patterns in passwords.
+type matcher struct {
+	dictionaries []*dictionary
+}
+
+// match returns all matches of all patterns in the password, sorted by position.
+func (m *matcher) match(password []rune) []*Match {
+	var matches []*Match
+
+	matches = append(matches, m.dictionaryMatches(password)...)
+	matches = append(matches, m.reverseDictionaryMatches(password)...)
+	matches = append(matches, m.l33tMatches(password)...)
+	matches = append(matches, spatialMatches(password)...)
+	matches = append(matches, m.repeatMatches(password)...)
+	matches = append(matches, sequenceMatches(password)...)
+	matches = append(matches, yearMatches(password)...)
+	matches = append(matches, dateMatches(password)...)
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].I != matches[b].I {
+			return matches[a].I < matches[b].I
+		}
+		return matches[a].J < matches[b].J
+	})
+
+	return matches
+}
+
+// dictionaryMatches returns every part of the password that is a word of a dictionary.
+func (m *matcher) dictionaryMatches(password []rune) []*Match {
+	lower := []rune(strings.ToLower(string(password)))
+	if len(lower) != len(password) {
+		// Lowercasing changed the number of runes, so positions cannot be mapped back.
+		lower = password
+	}
+
+	var matches []*Match
+	for i := range lower {
+		for j := i; j < len(lower); j++ {
+			word := string(lower[i : j+1])
+			for _, d := range m.dictionaries {
+				if rank, ok := d.ranks[word]; ok {
+					matches = append(matches, &Match{
+						Pattern:     PatternDictionary,
+						I:           i,
+						J:           j,
+						Token:       string(password[i : j+1]),
+						Dictionary:  d.name,
+						MatchedWord: word,
+						Rank:        rank,
+					})
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// reverseDictionaryMatches returns every part of the password that is a reversed word of a dictionary.
+func (m *matcher) reverseDictionaryMatches(password []rune) []*Match {
+	reversed := reverseRunes(password)
+
+	var matches []*Match
+	for _, match := range m.dictionaryMatches(reversed) {
+		// Map the positions back onto the original password.
+		i, j := len(password)-1-match.J, len(password)-1-match.I
+		match.I, match.J = i, j
+		match.Token = string(password[i : j+1])
+		match.Reversed = true
+
+		// Palindromes are already found by the regular dictionary matching.
+		if match.Token != string(reverseRunes([]rune(match.Token))) {
+			matches = append(matches, match)
+		}
+	}
+
+	return matches
+}
+
+// l33tMatches returns every part of the password that is a word of a dictionary after
+// reverting common l33t substitutions, like "p@ssw0rd".
+func (m *matcher) l33tMatches(password []rune) []*Match {
+	var matches []*Match
+	seen := make(map[string]struct{})
+
+	for _, sub := range l33tSubs(password) {
+		// Replace the substitutes with their letters.
+		translated := make([]rune, len(password))
+		for i, c := range password {
+			if letter, ok := sub[c]; ok {
+				translated[i] = letter
+			} else {
+				translated[i] = c
+			}
+		}
+
+		for _, match := range m.dictionaryMatches(translated) {
+			token := password[match.I : match.J+1]
+
+			// Only keep the substitutions that are used in the token.
+			used := make(map[rune]rune)
+			for _, c := range token {
+				if letter, ok := sub[c]; ok {
+					used[c] = letter
+				}
+			}
+
+			// Single characters and tokens without substitutions are not l33t.
+			if len(used) == 0 || len(token) <= 1 {
+				continue
+			}
+
+			// Different substitution maps can lead to the same match.
+			key := strconv.Itoa(match.I) + ":" + strconv.Itoa(match.J) + ":" + match.Dictionary + ":" + match.MatchedWord
+			if _, exists := seen[key]; exists {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			match.Token = string(token)
+			match.L33t = true
+			match.Sub = used
+			matches = append(matches, match)
+		}
+	}
+
+	return matches
+}
+
+// l33tSubs returns the combinations of l33t substitutions that can be reverted in the password.
+// Characters like '1' can stand for more than one letter, so every choice is a separate map.
+func l33tSubs(password []rune) []map[rune]rune {
+	// Collect the letters every substitute found in the password can stand for.
+	candidates := make(map[rune][]rune)
+	for letter, subs := range l33tTable {
+		for _, c := range subs {
+			if containsRune(password, c) {
+				candidates[c] = append(candidates[c], letter)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Iterate the substitutes in a fixed order, so the result is deterministic.
+	keys := make([]rune, 0, len(candidates))
+	for c := range candidates {
+		keys = append(keys, c)
+		sort.Slice(candidates[c], func(a, b int) bool { return candidates[c][a] < candidates[c][b] })
+	}
+	sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+
+	subs := []map[rune]rune{{}}
+	for _, c := range keys {
+		var next []map[rune]rune
+		for _, sub := range subs {
+			for _, letter := range candidates[c] {
+				extended := make(map[rune]rune, len(sub)+1)
+				for k, v := range sub {
+					extended[k] = v
+				}
+				extended[c] = letter
+				next = append(next, extended)
+				if len(next) >= maxL33tSubs {
+					break
+				}
+			}
+			if len(next) >= maxL33tSubs {
+				break
+			}
+		}
+		subs = next
+	}
+
+	return subs
+}
+
+// spatialMatches returns the keyboard walks of at least three keys in the password, such as "qwerty".
+func spatialMatches(password []rune) []*Match {
+	var matches []*Match
+
+	for _, k := range keyboards {
+		for i := 0; i < len(password)-1; {
+			j := i + 1
+			lastDirection := -1
+			turns := 0
+			shifted := 0
+
+			// The first character counts as shifted if it is typed with shift on a qwerty keyboard.
+			if k.name == "qwerty" && strings.ContainsRune(`~!@#$%^&*()_+QWERTYUIOP{}|ASDFGHJKL:"ZXCVBNM<>?`, password[i]) {
+				shifted = 1
+			}
+
+			for j < len(password) {
+				direction, isShifted := k.step(password[j-1], password[j])
+				if direction < 0 {
+					break
+				}
+				if isShifted {
+					shifted++
+				}
+				if direction != lastDirection {
+					turns++
+					lastDirection = direction
+				}
+				j++
+			}
+
+			// Walks of at least three keys are a match.
+			if j-i > 2 {
+				matches = append(matches, &Match{
+					Pattern:  PatternSpatial,
+					I:        i,
+					J:        j - 1,
+					Token:    string(password[i:j]),
+					Keyboard: k.name,
+					Turns:    turns,
+					Shifted:  shifted,
+				})
+			}
+
+			i = j
+		}
+	}
+
+	return matches
+}
+
+// repeatMatches returns the parts of the password that repeat a base token, such as "aaa" or "abcabc".
+func (m *matcher) repeatMatches(password []rune) []*Match {
+	var matches []*Match
+
+	for i := 0; i < len(password); {
+		// Find the longest repetition starting at i, preferring the shortest base token.
+		bestSpan, bestBase := 0, 0
+		for base := 1; i+2*base <= len(password); base++ {
+			count := 1
+			for i+(count+1)*base <= len(password) &&
+				string(password[i+count*base:i+(count+1)*base]) == string(password[i:i+base]) {
+				count++
+			}
+			if count >= 2 && count*base > bestSpan {
+				bestSpan, bestBase = count*base, base
+			}
+		}
+
+		if bestSpan == 0 {
+			i++
+			continue
+		}
+
+		base := password[i : i+bestBase]
+		baseGuesses, _ := m.mostGuessable(base)
+		matches = append(matches, &Match{
+			Pattern:     PatternRepeat,
+			I:           i,
+			J:           i + bestSpan - 1,
+			Token:       string(password[i : i+bestSpan]),
+			BaseToken:   string(base),
+			RepeatCount: bestSpan / bestBase,
+			BaseGuesses: baseGuesses,
+		})
+		i += bestSpan
+	}
+
+	return matches
+}
+
+// sequenceMatches returns the parts of the password whose characters follow each other
+// with a constant step, such as "abc", "9753" or "ZYX".
+func sequenceMatches(password []rune) []*Match {
+	if len(password) <= 1 {
+		return nil
+	}
+
+	var matches []*Match
+	add := func(i, j, delta int) {
+		if j-i <= 1 && abs(delta) != 1 {
+			return
+		}
+		if delta == 0 || abs(delta) > maxSequenceDelta {
+			return
+		}
+
+		token := string(password[i : j+1])
+		name := "unicode"
+		switch {
+		case strings.Trim(token, Lowers) == "":
+			name = "lower"
+		case strings.Trim(token, Uppers) == "":
+			name = "upper"
+		case strings.Trim(token, Digits) == "":
+			name = "digits"
+		}
+
+		matches = append(matches, &Match{
+			Pattern:      PatternSequence,
+			I:            i,
+			J:            j,
+			Token:        token,
+			SequenceName: name,
+			Ascending:    delta > 0,
+		})
+	}
+
+	i := 0
+	lastDelta := int(password[1]) - int(password[0])
+	for k := 2; k < len(password); k++ {
+		delta := int(password[k]) - int(password[k-1])
+		if delta == lastDelta {
+			continue
+		}
+		add(i, k-1, lastDelta)
+		i = k - 1
+		lastDelta = delta
+	}
+	add(i, len(password)-1, lastDelta)
+
+	return matches
+}
+
+// yearMatches returns the recent years in the password, such as "1987" or "2024".
+func yearMatches(password []rune) []*Match {
+	var matches []*Match
+
+	s := string(password)
+	for _, loc := range recentYear.FindAllStringIndex(s, -1) {
+		token := s[loc[0]:loc[1]]
+		year, _ := strconv.Atoi(token)
+
+		// The years consist of ASCII digits, so byte offsets can be turned into rune positions.
+		i := len([]rune(s[:loc[0]]))
+		matches = append(matches, &Match{
+			Pattern: PatternYear,
+			I:       i,
+			J:       i + len(token) - 1,
+			Token:   token,
+			Year:    year,
+		})
+	}
+
+	return matches
+}
+
+// dateMatches returns the dates in the password, with or without separators,
+// such as "13.04.1987", "2001-9-11" or "130487".
+func dateMatches(password []rune) []*Match {
+	var matches []*Match
+
+	// Dates without separators consist of four to eight digits.
+	for i := range password {
+		for j := i + 3; j <= i+7 && j < len(password); j++ {
+			token := string(password[i : j+1])
+			if strings.Trim(token, Digits) != "" {
+				continue
+			}
+
+			// Among all ways to split the digits, pick the date closest to the reference year.
+			var best *Match
+			for _, split := range dateSplits[len(token)] {
+				k, l := split[0], split[1]
+				ints := []int{atoi(token[:k]), atoi(token[k:l]), atoi(token[l:])}
+				year, month, day, ok := mapIntsToDate(ints)
+				if !ok {
+					continue
+				}
+				if best == nil || abs(year-referenceYear) < abs(best.Year-referenceYear) {
+					best = &Match{Pattern: PatternDate, I: i, J: j, Token: token, Year: year, Month: month, Day: day}
+				}
+			}
+			if best != nil {
+				matches = append(matches, best)
+			}
+		}
+	}
+
+	// Dates with separators consist of six to ten characters.
+	for i := range password {
+		for j := i + 5; j <= i+9 && j < len(password); j++ {
+			token := string(password[i : j+1])
+			parts := dateWithSeparator.FindStringSubmatch(token)
+			if parts == nil || parts[2] != parts[4] {
+				continue
+			}
+
+			year, month, day, ok := mapIntsToDate([]int{atoi(parts[1]), atoi(parts[3]), atoi(parts[5])})
+			if !ok {
+				continue
+			}
+			matches = append(matches, &Match{
+				Pattern:   PatternDate,
+				I:         i,
+				J:         j,
+				Token:     token,
+				Year:      year,
+				Month:     month,
+				Day:       day,
+				Separator: parts[2],
+			})
+		}
+	}
+
+	// Drop dates that are part of longer dates, like "1987" in "13041987".
+	var filtered []*Match
+	for _, match := range matches {
+		contained := false
+		for _, other := range matches {
+			if other != match && other.I <= match.I && other.J >= match.J && other.J-other.I > match.J-match.I {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			filtered = append(filtered, match)
+		}
+	}
+
+	return filtered
+}
+
+// mapIntsToDate interprets three numbers as a date in any common order of
+// day, month and year. Two digit years are mapped onto 1951 to 2050.
+func mapIntsToDate(ints []int) (year, month, day int, ok bool) {
+	// The middle number is always a day or a month.
+	if ints[1] > 31 || ints[1] <= 0 {
+		return 0, 0, 0, false
+	}
+
+	var over12, over31, under1 int
+	for _, n := range ints {
+		if (n > 99 && n < dateMinYear) || n > dateMaxYear {
+			return 0, 0, 0, false
+		}
+		if n > 31 {
+			over31++
+		}
+		if n > 12 {
+			over12++
+		}
+		if n <= 0 {
+			under1++
+		}
+	}
+	if over31 >= 2 || over12 == 3 || under1 >= 2 {
+		return 0, 0, 0, false
+	}
+
+	// The year is either the first or the last number.
+	splits := []struct {
+		year int
+		rest [2]int
+	}{
+		{ints[2], [2]int{ints[0], ints[1]}},
+		{ints[0], [2]int{ints[1], ints[2]}},
+	}
+
+	// Prefer four digit years.
+	for _, s := range splits {
+		if s.year >= dateMinYear && s.year <= dateMaxYear {
+			month, day, ok := mapIntsToDayMonth(s.rest)
+			return s.year, month, day, ok
+		}
+	}
+
+	for _, s := range splits {
+		if month, day, ok := mapIntsToDayMonth(s.rest); ok {
+			year := s.year
+			switch {
+			case year > 99:
+			case year > 50:
+				year += 1900
+			default:
+				year += 2000
+			}
+			return year, month, day, true
+		}
+	}
+
+	return 0, 0, 0, false
+}
+
+// mapIntsToDayMonth interprets two numbers as day and month in either order.
+func mapIntsToDayMonth(ints [2]int) (month, day int, ok bool) {
+	for _, dm := range [][2]int{{ints[0], ints[1]}, {ints[1], ints[0]}} {
+		if dm[0] >= 1 && dm[0] <= 31 && dm[1] >= 1 && dm[1] <= 12 {
+			return dm[1], dm[0], true
+		}
+	}
+	return 0, 0, false
+}
+
+// reverseRunes returns the runes in reverse order.
+func reverseRunes(r []rune) []rune {
+	reversed := make([]rune, len(r))
+	for i, c := range r {
+		reversed[len(r)-1-i] = c
+	}
+	return reversed
+}
+
+// containsRune reports whether c is part of r.
+func containsRune(r []rune, c rune) bool {
+	for _, x := range r {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
+
+// isUpperRunes reports whether r contains only uppercase letters.
+func isUpperRunes(r []rune) bool {
+	for _, c := range r {
+		if !unicode.IsUpper(c) {
+			return false
+		}
+	}
+	return len(r) > 0
+}
+
+// atoi converts a string of digits into a number.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
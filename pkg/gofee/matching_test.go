@@ -0,0 +1,185 @@
+package gofee
+
+import (
+	"testing"
+)
+
+// testMatcher returns a matcher with a small dictionary, so the tests do not depend on the embedded lists.
+func testMatcher() *matcher {
+	return &matcher{dictionaries: []*dictionary{
+		newDictionary("test", []string{"password", "horse", "pass", "word"}),
+	}}
+}
+
+// hasMatch reports whether the matches contain the pattern at the given positions.
+func hasMatch(matches []*Match, pattern string, i, j int) *Match {
+	for _, m := range matches {
+		if m.Pattern == pattern && m.I == i && m.J == j {
+			return m
+		}
+	}
+	return nil
+}
+
+// TestDictionaryMatches checks that words are found case-insensitively at every position.
+func TestDictionaryMatches(t *testing.T) {
+	matches := testMatcher().dictionaryMatches([]rune("xPassWord"))
+
+	for _, want := range []struct {
+		i, j int
+		word string
+		rank int
+	}{
+		{1, 8, "password", 1},
+		{1, 4, "pass", 3},
+		{5, 8, "word", 4},
+	} {
+		m := hasMatch(matches, PatternDictionary, want.i, want.j)
+		if m == nil || m.MatchedWord != want.word || m.Rank != want.rank {
+			t.Errorf("dictionaryMatches() = %+v, want %s at %d-%d", m, want.word, want.i, want.j)
+		}
+	}
+}
+
+// TestReverseDictionaryMatches checks that reversed words are mapped back onto the password.
+func TestReverseDictionaryMatches(t *testing.T) {
+	matches := testMatcher().reverseDictionaryMatches([]rune("12esroh"))
+
+	m := hasMatch(matches, PatternDictionary, 2, 6)
+	if m == nil || !m.Reversed || m.Token != "esroh" || m.MatchedWord != "horse" {
+		t.Errorf("reverseDictionaryMatches() = %+v, want reversed horse at 2-6", m)
+	}
+}
+
+// TestL33tMatches checks that common substitutions are reverted.
+func TestL33tMatches(t *testing.T) {
+	matches := testMatcher().l33tMatches([]rune("p4$$w0rd"))
+
+	m := hasMatch(matches, PatternDictionary, 0, 7)
+	if m == nil || !m.L33t || m.MatchedWord != "password" {
+		t.Fatalf("l33tMatches() = %+v, want l33t password at 0-7", m)
+	}
+	want := map[rune]rune{'4': 'a', '$': 's', '0': 'o'}
+	for sub, letter := range want {
+		if m.Sub[sub] != letter {
+			t.Errorf("l33tMatches() sub = %v, want %v", m.Sub, want)
+		}
+	}
+}
+
+// TestSpatialMatches checks the detection of keyboard walks.
+func TestSpatialMatches(t *testing.T) {
+	tests := []struct {
+		password    string
+		i, j        int
+		keyboard    string
+		turns       int
+		shifted     int
+		wantNoMatch bool
+	}{
+		{password: "qwerty", i: 0, j: 5, keyboard: "qwerty", turns: 1},
+		{password: "11zxcvfr", i: 2, j: 7, keyboard: "qwerty", turns: 2},
+		{password: "QWErty", i: 0, j: 5, keyboard: "qwerty", turns: 1, shifted: 3},
+		{password: "7412", i: 0, j: 3, keyboard: "keypad", turns: 2},
+		{password: "qa", wantNoMatch: true},
+	}
+
+	for _, tt := range tests {
+		matches := spatialMatches([]rune(tt.password))
+		m := hasMatch(matches, PatternSpatial, tt.i, tt.j)
+
+		if tt.wantNoMatch {
+			if len(matches) != 0 {
+				t.Errorf("spatialMatches(%q) = %+v, want no matches", tt.password, matches[0])
+			}
+			continue
+		}
+		if m == nil || m.Keyboard != tt.keyboard || m.Turns != tt.turns || m.Shifted != tt.shifted {
+			t.Errorf("spatialMatches(%q) = %+v, want %s walk at %d-%d with %d turns and %d shifted",
+				tt.password, m, tt.keyboard, tt.i, tt.j, tt.turns, tt.shifted)
+		}
+	}
+}
+
+// TestRepeatMatches checks the detection of repeated characters and tokens.
+func TestRepeatMatches(t *testing.T) {
+	tests := []struct {
+		password string
+		i, j     int
+		base     string
+		count    int
+	}{
+		{password: "aaaa", i: 0, j: 3, base: "a", count: 4},
+		{password: "xabcabcabc", i: 1, j: 9, base: "abc", count: 3},
+		{password: "abababab", i: 0, j: 7, base: "ab", count: 4},
+	}
+
+	for _, tt := range tests {
+		m := hasMatch(testMatcher().repeatMatches([]rune(tt.password)), PatternRepeat, tt.i, tt.j)
+		if m == nil || m.BaseToken != tt.base || m.RepeatCount != tt.count || m.BaseGuesses <= 0 {
+			t.Errorf("repeatMatches(%q) = %+v, want %d times %q", tt.password, m, tt.count, tt.base)
+		}
+	}
+}
+
+// TestSequenceMatches checks the detection of sequences.
+func TestSequenceMatches(t *testing.T) {
+	tests := []struct {
+		password  string
+		i, j      int
+		name      string
+		ascending bool
+	}{
+		{password: "abcdef", i: 0, j: 5, name: "lower", ascending: true},
+		{password: "xx9753", i: 2, j: 5, name: "digits", ascending: false},
+		{password: "ZYX", i: 0, j: 2, name: "upper", ascending: false},
+	}
+
+	for _, tt := range tests {
+		m := hasMatch(sequenceMatches([]rune(tt.password)), PatternSequence, tt.i, tt.j)
+		if m == nil || m.SequenceName != tt.name || m.Ascending != tt.ascending {
+			t.Errorf("sequenceMatches(%q) = %+v, want %s sequence at %d-%d", tt.password, m, tt.name, tt.i, tt.j)
+		}
+	}
+
+	if matches := sequenceMatches([]rune("a")); len(matches) != 0 {
+		t.Errorf("sequenceMatches(\"a\") = %v, want no matches", matches)
+	}
+}
+
+// TestYearMatches checks the detection of recent years.
+func TestYearMatches(t *testing.T) {
+	m := hasMatch(yearMatches([]rune("älter1987")), PatternYear, 5, 8)
+	if m == nil || m.Year != 1987 {
+		t.Errorf("yearMatches() = %+v, want 1987 at 5-8", m)
+	}
+}
+
+// TestDateMatches checks the detection of dates with and without separators.
+func TestDateMatches(t *testing.T) {
+	tests := []struct {
+		password         string
+		i, j             int
+		year, month, day int
+		separator        string
+	}{
+		{password: "13.04.1987", i: 0, j: 9, year: 1987, month: 4, day: 13, separator: "."},
+		{password: "x2001-9-13", i: 1, j: 9, year: 2001, month: 9, day: 13, separator: "-"},
+		{password: "130487", i: 0, j: 5, year: 1987, month: 4, day: 13},
+		{password: "1/1/91", i: 0, j: 5, year: 1991, month: 1, day: 1, separator: "/"},
+	}
+
+	for _, tt := range tests {
+		m := hasMatch(dateMatches([]rune(tt.password)), PatternDate, tt.i, tt.j)
+		if m == nil || m.Year != tt.year || m.Month != tt.month || m.Day != tt.day || m.Separator != tt.separator {
+			t.Errorf("dateMatches(%q) = %+v, want %d-%d-%d at %d-%d", tt.password, m, tt.year, tt.month, tt.day, tt.i, tt.j)
+		}
+	}
+
+	// Mixed separators and impossible dates are no dates.
+	for _, password := range []string{"13.04-1987", "99.99.9999"} {
+		if matches := dateMatches([]rune(password)); hasMatch(matches, PatternDate, 0, len(password)-1) != nil {
+			t.Errorf("dateMatches(%q) found a date", password)
+		}
+	}
+}
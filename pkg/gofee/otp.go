@@ -0,0 +1,298 @@
+package gofee
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of one-time passwords, which are supported by all authenticator apps.
+const (
+	OTPSecretSize = 20 // The size of secrets in bytes, 160 bits as recommended by RFC 4226.
+	OTPDigits     = 6
+	OTPPeriod     = 30 * time.Second
+	OTPAlgorithm  = "SHA1"
+)
+
+// Limits of one-time passwords.
+const (
+	OTPMinSecretSize = 16 // RFC 4226 requires secrets of at least 128 bits.
+	OTPMinDigits     = 6
+	OTPMaxDigits     = 8
+)
+
+// Types of one-time passwords.
+const (
+	OTPTypeTOTP = "totp" // Time-based one-time passwords of RFC 6238.
+	OTPTypeHOTP = "hotp" // Counter-based one-time passwords of RFC 4226.
+)
+
+// otpAlgorithms maps the names of the supported HMAC algorithms to their hash functions.
+var otpAlgorithms = map[string]func() hash.Hash{
+	"SHA1":   sha1.New,
+	"SHA256": sha256.New,
+	"SHA512": sha512.New,
+}
+
+// otpEncoding encodes secrets as Base32 without padding, as expected by authenticator apps.
+var otpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// OTPKey describes a one-time password as exchanged with authenticator apps by otpauth:// URIs.
+type OTPKey struct {
+	Type      string        // OTPTypeTOTP or OTPTypeHOTP.
+	Issuer    string        // The provider of the account, such as Example, which may be empty.
+	Account   string        // The name of the account, such as alice@example.com.
+	Secret    string        // The shared secret in Base32.
+	Algorithm string        // The HMAC algorithm, SHA1, SHA256 or SHA512.
+	Digits    int           // The number of digits of the codes.
+	Period    time.Duration // The time a TOTP code is valid for.
+	Counter   uint64        // The counter of the next HOTP code.
+}
+
+// NewOTPKey returns a TOTP key for the account with a new secret and the default settings.
+func NewOTPKey(issuer, account string) (OTPKey, error) {
+	return defaultGenerator.NewOTPKey(issuer, account)
+}
+
+// NewOTPKey returns a TOTP key for the account with a new secret and the default settings.
+func (g *Generator) NewOTPKey(issuer, account string) (OTPKey, error) {
+	secret, err := g.NewOTPSecret(OTPSecretSize)
+	if err != nil {
+		return OTPKey{}, err
+	}
+	return OTPKey{
+		Type:      OTPTypeTOTP,
+		Issuer:    issuer,
+		Account:   account,
+		Secret:    secret,
+		Algorithm: OTPAlgorithm,
+		Digits:    OTPDigits,
+		Period:    OTPPeriod,
+	}, nil
+}
+
+// NewOTPSecret returns a random secret of size bytes encoded in Base32 using the default Generator.
+func NewOTPSecret(size int) (string, error) {
+	return defaultGenerator.NewOTPSecret(size)
+}
+
+// NewOTPSecret returns a random secret of size bytes encoded in Base32.
+func (g *Generator) NewOTPSecret(size int) (string, error) {
+	if size < OTPMinSecretSize {
+		return "", fmt.Errorf("secret must have at least %d bytes", OTPMinSecretSize)
+	}
+
+	secret, err := g.randomBytes(size)
+	if err != nil {
+		return "", err
+	}
+	return otpEncoding.EncodeToString(secret), nil
+}
+
+// DecodeOTPSecret decodes a Base32 secret. Case, spaces and padding are ignored, as secrets
+// are often typed in groups of four characters.
+func DecodeOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.Join(strings.Fields(secret), ""))
+	secret = strings.TrimRight(secret, "=")
+	if secret == "" {
+		return nil, fmt.Errorf("secret is empty")
+	}
+	key, err := otpEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("secret is not valid Base32: %v", err)
+	}
+	return key, nil
+}
+
+// HOTP returns the code of RFC 4226 for the counter.
+func HOTP(secret []byte, counter uint64, digits int, algorithm string) (string, error) {
+	if digits < OTPMinDigits || digits > OTPMaxDigits {
+		return "", fmt.Errorf("codes must have %d to %d digits", OTPMinDigits, OTPMaxDigits)
+	}
+	newHash, ok := otpAlgorithms[algorithm]
+	if !ok {
+		return "", fmt.Errorf("unsupported algorithm %q (supported: SHA1, SHA256, SHA512)", algorithm)
+	}
+
+	mac := hmac.New(newHash, secret)
+	mac.Write(binary.BigEndian.AppendUint64(nil, counter))
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation: the low 4 bits of the last byte select 4 bytes of the sum, whose
+	// value without the sign bit is reduced to the number of digits.
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+
+	modulus := uint32(1)
+	for i := 0; i < digits; i++ {
+		modulus *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%modulus), nil
+}
+
+// TOTP returns the code of RFC 6238 at the time t, which is the HOTP code of the number of
+// periods since the Unix epoch.
+func TOTP(secret []byte, t time.Time, period time.Duration, digits int, algorithm string) (string, error) {
+	counter, err := totpCounter(t, period)
+	if err != nil {
+		return "", err
+	}
+	return HOTP(secret, counter, digits, algorithm)
+}
+
+// totpCounter returns the number of periods since the Unix epoch at the time t.
+func totpCounter(t time.Time, period time.Duration) (uint64, error) {
+	if period < time.Second || period%time.Second != 0 {
+		return 0, fmt.Errorf("period must be a positive number of seconds")
+	}
+	if t.Unix() < 0 {
+		return 0, fmt.Errorf("time must not be before the Unix epoch")
+	}
+	return uint64(t.Unix()) / uint64(period/time.Second), nil
+}
+
+// Code returns the code of the key at the time t. HOTP codes ignore the time and use the counter.
+func (k OTPKey) Code(t time.Time) (string, error) {
+	secret, err := DecodeOTPSecret(k.Secret)
+	if err != nil {
+		return "", err
+	}
+
+	switch k.Type {
+	case OTPTypeTOTP:
+		return TOTP(secret, t, k.Period, k.Digits, k.Algorithm)
+	case OTPTypeHOTP:
+		return HOTP(secret, k.Counter, k.Digits, k.Algorithm)
+	}
+	return "", fmt.Errorf("unknown type %q (supported: %s, %s)", k.Type, OTPTypeTOTP, OTPTypeHOTP)
+}
+
+// Remaining returns the time the TOTP code at the time t stays valid.
+func (k OTPKey) Remaining(t time.Time) time.Duration {
+	counter, err := totpCounter(t, k.Period)
+	if err != nil {
+		return 0
+	}
+	end := time.Unix(int64((counter+1)*uint64(k.Period/time.Second)), 0)
+	return end.Sub(t)
+}
+
+// validate checks that authenticator apps support the settings of the key.
+func (k OTPKey) validate() error {
+	if k.Type != OTPTypeTOTP && k.Type != OTPTypeHOTP {
+		return fmt.Errorf("unknown type %q (supported: %s, %s)", k.Type, OTPTypeTOTP, OTPTypeHOTP)
+	}
+	if k.Account == "" {
+		return fmt.Errorf("account must not be empty")
+	}
+	if strings.Contains(k.Issuer, ":") || strings.Contains(k.Account, ":") {
+		return fmt.Errorf("issuer and account must not contain a colon")
+	}
+	if _, err := DecodeOTPSecret(k.Secret); err != nil {
+		return err
+	}
+	if _, ok := otpAlgorithms[k.Algorithm]; !ok {
+		return fmt.Errorf("unsupported algorithm %q (supported: SHA1, SHA256, SHA512)", k.Algorithm)
+	}
+	if k.Digits < OTPMinDigits || k.Digits > OTPMaxDigits {
+		return fmt.Errorf("codes must have %d to %d digits", OTPMinDigits, OTPMaxDigits)
+	}
+	if k.Type == OTPTypeTOTP && (k.Period < time.Second || k.Period%time.Second != 0) {
+		return fmt.Errorf("period must be a positive number of seconds")
+	}
+	return nil
+}
+
+// URI returns the otpauth:// URI of the key, such as
+// otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example&algorithm=SHA1&digits=6&period=30
+// which authenticator apps read from QR codes.
+func (k OTPKey) URI() (string, error) {
+	if err := k.validate(); err != nil {
+		return "", err
+	}
+
+	label := url.PathEscape(k.Account)
+	params := []string{"secret=" + otpEscape(strings.TrimRight(strings.ToUpper(k.Secret), "="))}
+	if k.Issuer != "" {
+		label = url.PathEscape(k.Issuer) + ":" + label
+		params = append(params, "issuer="+otpEscape(k.Issuer))
+	}
+	params = append(params, "algorithm="+k.Algorithm, "digits="+strconv.Itoa(k.Digits))
+	if k.Type == OTPTypeTOTP {
+		params = append(params, "period="+strconv.Itoa(int(k.Period/time.Second)))
+	} else {
+		params = append(params, "counter="+strconv.FormatUint(k.Counter, 10))
+	}
+
+	return "otpauth://" + k.Type + "/" + label + "?" + strings.Join(params, "&"), nil
+}
+
+// otpEscape escapes a query parameter of an otpauth:// URI. Spaces are escaped as %20, as
+// some authenticator apps show a + literally.
+func otpEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// ParseOTPURI parses an otpauth:// URI. Missing settings have their default values.
+func ParseOTPURI(uri string) (OTPKey, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return OTPKey{}, fmt.Errorf("invalid URI: %v", err)
+	}
+	if u.Scheme != "otpauth" {
+		return OTPKey{}, fmt.Errorf("invalid URI: scheme must be otpauth")
+	}
+
+	query := u.Query()
+	k := OTPKey{
+		Type:      strings.ToLower(u.Host),
+		Account:   strings.TrimPrefix(u.Path, "/"),
+		Secret:    query.Get("secret"),
+		Algorithm: OTPAlgorithm,
+		Digits:    OTPDigits,
+	}
+	if issuer, account, ok := strings.Cut(k.Account, ":"); ok {
+		k.Issuer, k.Account = issuer, strings.TrimSpace(account)
+	}
+	if issuer := query.Get("issuer"); issuer != "" {
+		k.Issuer = issuer
+	}
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		k.Algorithm = strings.ToUpper(algorithm)
+	}
+	if digits := query.Get("digits"); digits != "" {
+		if k.Digits, err = strconv.Atoi(digits); err != nil {
+			return OTPKey{}, fmt.Errorf("invalid URI: invalid digits %q", digits)
+		}
+	}
+	if period := query.Get("period"); period != "" {
+		seconds, err := strconv.Atoi(period)
+		if err != nil {
+			return OTPKey{}, fmt.Errorf("invalid URI: invalid period %q", period)
+		}
+		k.Period = time.Duration(seconds) * time.Second
+	} else if k.Type == OTPTypeTOTP {
+		k.Period = OTPPeriod
+	}
+	if counter := query.Get("counter"); counter != "" {
+		if k.Counter, err = strconv.ParseUint(counter, 10, 64); err != nil {
+			return OTPKey{}, fmt.Errorf("invalid URI: invalid counter %q", counter)
+		}
+	} else if k.Type == OTPTypeHOTP {
+		return OTPKey{}, fmt.Errorf("invalid URI: counter is required for HOTP")
+	}
+
+	if err := k.validate(); err != nil {
+		return OTPKey{}, fmt.Errorf("invalid URI: %v", err)
+	}
+	return k, nil
+}
@@ -0,0 +1,260 @@
+package gofee
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHOTP checks the test vectors of RFC 4226, appendix D.
+func TestHOTP(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	for counter, code := range want {
+		got, err := HOTP(secret, uint64(counter), 6, "SHA1")
+		if err != nil {
+			t.Fatalf("HOTP() error = %v", err)
+		}
+		if got != code {
+			t.Errorf("HOTP(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+// TestTOTP checks the test vectors of RFC 6238, appendix B.
+func TestTOTP(t *testing.T) {
+	// The secrets of the algorithms have the sizes of their hashes.
+	secrets := map[string][]byte{
+		"SHA1":   []byte("12345678901234567890"),
+		"SHA256": []byte("12345678901234567890123456789012"),
+		"SHA512": []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+
+	tests := []struct {
+		time      int64
+		algorithm string
+		want      string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1111111109, "SHA256", "68084774"},
+		{1111111109, "SHA512", "25091201"},
+		{1111111111, "SHA1", "14050471"},
+		{1111111111, "SHA256", "67062674"},
+		{1111111111, "SHA512", "99943326"},
+		{1234567890, "SHA1", "89005924"},
+		{1234567890, "SHA256", "91819424"},
+		{1234567890, "SHA512", "93441116"},
+		{2000000000, "SHA1", "69279037"},
+		{2000000000, "SHA256", "90698825"},
+		{2000000000, "SHA512", "38618901"},
+		{20000000000, "SHA1", "65353130"},
+		{20000000000, "SHA256", "77737706"},
+		{20000000000, "SHA512", "47863826"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm+"/"+time.Unix(tt.time, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			got, err := TOTP(secrets[tt.algorithm], time.Unix(tt.time, 0), 30*time.Second, 8, tt.algorithm)
+			if err != nil {
+				t.Fatalf("TOTP() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TOTP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOTPErrors checks that unsupported settings are rejected.
+func TestOTPErrors(t *testing.T) {
+	secret := []byte("12345678901234567890")
+
+	if _, err := HOTP(secret, 0, 5, "SHA1"); err == nil {
+		t.Errorf("HOTP() with 5 digits succeeded")
+	}
+	if _, err := HOTP(secret, 0, 9, "SHA1"); err == nil {
+		t.Errorf("HOTP() with 9 digits succeeded")
+	}
+	if _, err := HOTP(secret, 0, 6, "MD5"); err == nil {
+		t.Errorf("HOTP() with MD5 succeeded")
+	}
+	if _, err := TOTP(secret, time.Unix(59, 0), 1500*time.Millisecond, 6, "SHA1"); err == nil {
+		t.Errorf("TOTP() with a fractional period succeeded")
+	}
+	if _, err := TOTP(secret, time.Unix(-1, 0), 30*time.Second, 6, "SHA1"); err == nil {
+		t.Errorf("TOTP() before the epoch succeeded")
+	}
+}
+
+// TestNewOTPSecret checks that secrets are read from the Generator and encoded in Base32.
+func TestNewOTPSecret(t *testing.T) {
+	g := NewGenerator(bytes.NewReader([]byte("12345678901234567890")))
+	secret, err := g.NewOTPSecret(20)
+	if err != nil {
+		t.Fatalf("NewOTPSecret() error = %v", err)
+	}
+	if want := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"; secret != want {
+		t.Errorf("NewOTPSecret() = %s, want %s", secret, want)
+	}
+
+	// Sizes which are not multiples of 5 bytes are encoded without padding.
+	secret, err = NewOTPSecret(16)
+	if err != nil {
+		t.Fatalf("NewOTPSecret() error = %v", err)
+	}
+	if len(secret) != 26 || strings.Contains(secret, "=") {
+		t.Errorf("NewOTPSecret() = %s, want 26 characters without padding", secret)
+	}
+
+	if _, err := NewOTPSecret(OTPMinSecretSize - 1); err == nil {
+		t.Errorf("NewOTPSecret() with %d bytes succeeded", OTPMinSecretSize-1)
+	}
+}
+
+// TestDecodeOTPSecret checks that typed secrets are decoded.
+func TestDecodeOTPSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		want    string
+		wantErr bool
+	}{
+		{"Plain", "GEZDGNBVGY3TQOJQ", "1234567890", false},
+		{"Lowercase groups", "gezd gnbv gy3t qojq", "1234567890", false},
+		{"Padding", "GEZDGNBVGY3TQOJQGE======", "12345678901", false},
+		{"Empty", " ", "", true},
+		{"Invalid character", "GEZDGNBVGY3TQOJ1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeOTPSecret(tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeOTPSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("DecodeOTPSecret() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOTPKeyURI checks the otpauth:// URIs of keys and that they parse back into the keys.
+func TestOTPKeyURI(t *testing.T) {
+	tests := []struct {
+		name string
+		key  OTPKey
+		want string
+	}{
+		{
+			"TOTP",
+			OTPKey{Type: OTPTypeTOTP, Issuer: "Example", Account: "alice@example.com", Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6, Period: 30 * time.Second},
+			"otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example&algorithm=SHA1&digits=6&period=30",
+		},
+		{
+			"HOTP",
+			OTPKey{Type: OTPTypeHOTP, Account: "alice", Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA256", Digits: 8, Counter: 42},
+			"otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP&algorithm=SHA256&digits=8&counter=42",
+		},
+		{
+			"Spaces",
+			OTPKey{Type: OTPTypeTOTP, Issuer: "Big Corp", Account: "alice smith", Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA512", Digits: 6, Period: 60 * time.Second},
+			"otpauth://totp/Big%20Corp:alice%20smith?secret=JBSWY3DPEHPK3PXP&issuer=Big%20Corp&algorithm=SHA512&digits=6&period=60",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.key.URI()
+			if err != nil {
+				t.Fatalf("URI() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("URI() = %s, want %s", got, tt.want)
+			}
+
+			key, err := ParseOTPURI(got)
+			if err != nil {
+				t.Fatalf("ParseOTPURI() error = %v", err)
+			}
+			if key != tt.key {
+				t.Errorf("ParseOTPURI() = %+v, want %+v", key, tt.key)
+			}
+		})
+	}
+}
+
+// TestParseOTPURI checks the defaults and errors of parsed URIs.
+func TestParseOTPURI(t *testing.T) {
+	key, err := ParseOTPURI("otpauth://TOTP/alice?secret=jbswy3dpehpk3pxp")
+	if err != nil {
+		t.Fatalf("ParseOTPURI() error = %v", err)
+	}
+	want := OTPKey{Type: OTPTypeTOTP, Account: "alice", Secret: "jbswy3dpehpk3pxp", Algorithm: OTPAlgorithm, Digits: OTPDigits, Period: OTPPeriod}
+	if key != want {
+		t.Errorf("ParseOTPURI() = %+v, want %+v", key, want)
+	}
+
+	tests := []struct {
+		name    string
+		uri     string
+		wantErr string
+	}{
+		{"Scheme", "https://totp/alice?secret=JBSWY3DPEHPK3PXP", "scheme"},
+		{"Type", "otpauth://motp/alice?secret=JBSWY3DPEHPK3PXP", "type"},
+		{"Missing secret", "otpauth://totp/alice", "secret"},
+		{"Invalid secret", "otpauth://totp/alice?secret=123", "Base32"},
+		{"Missing account", "otpauth://totp/?secret=JBSWY3DPEHPK3PXP", "account"},
+		{"Missing counter", "otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP", "counter"},
+		{"Digits", "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&digits=10", "digits"},
+		{"Period", "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&period=0", "period"},
+		{"Algorithm", "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&algorithm=MD5", "algorithm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOTPURI(tt.uri)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseOTPURI() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestOTPKeyCode checks the codes of TOTP and HOTP keys.
+func TestOTPKeyCode(t *testing.T) {
+	// The RFC 6238 secret 12345678901234567890 in Base32.
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	totp := OTPKey{Type: OTPTypeTOTP, Secret: secret, Algorithm: "SHA1", Digits: 8, Period: 30 * time.Second}
+	if got, err := totp.Code(time.Unix(1111111109, 0)); err != nil || got != "07081804" {
+		t.Errorf("Code() = %s, %v, want 07081804", got, err)
+	}
+	if got := totp.Remaining(time.Unix(1111111109, 0)); got != 1*time.Second {
+		t.Errorf("Remaining() = %s, want 1s", got)
+	}
+
+	hotp := OTPKey{Type: OTPTypeHOTP, Secret: secret, Algorithm: "SHA1", Digits: 6, Counter: 1}
+	if got, err := hotp.Code(time.Now()); err != nil || got != "287082" {
+		t.Errorf("Code() = %s, %v, want 287082", got, err)
+	}
+}
+
+// TestNewOTPKey checks that new keys have the default settings and a valid URI.
+func TestNewOTPKey(t *testing.T) {
+	key, err := NewOTPKey("Example", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewOTPKey() error = %v", err)
+	}
+	if key.Type != OTPTypeTOTP || key.Algorithm != OTPAlgorithm || key.Digits != OTPDigits || key.Period != OTPPeriod || len(key.Secret) != 32 {
+		t.Errorf("NewOTPKey() = %+v, want the default settings", key)
+	}
+	if _, err := key.URI(); err != nil {
+		t.Errorf("URI() error = %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package gofee
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// DefaultSeparator is the separator of the words of memorable passwords, unless another one is given.
+const DefaultSeparator = "-"
+
+// PassphraseConfig holds the options for diceware-style passphrases.
+type PassphraseConfig struct {
+	Separator  string // Separator placed between the words.
+	Capitalize bool   // Capitalize the first letter of every word.
+	AddDigit   bool   // Append a random digit to a random word.
+	AddSymbol  bool   // Append a random symbol to a random word.
+
+	// The characters appended by AddDigit and AddSymbol, Digits and Symbols if empty.
+	Digits  string
+	Symbols string
+}
+
+// injectedChars returns the digits and symbols appended to passphrases.
+func (config PassphraseConfig) injectedChars() (digits, symbols string) {
+	digits, symbols = Digits, Symbols
+	if config.Digits != "" {
+		digits = config.Digits
+	}
+	if config.Symbols != "" {
+		symbols = config.Symbols
+	}
+	return digits, symbols
+}
+
+// withPasswordConfig returns the config with the injected characters of a PasswordConfig,
+// which are its custom symbols without the excluded and ambiguous characters. It returns an
+// error if no digits or symbols are left for AddDigit or AddSymbol.
+func (config PassphraseConfig) withPasswordConfig(pc PasswordConfig) (PassphraseConfig, error) {
+	pc = pc.normalize()
+	if err := pc.validate(); err != nil {
+		return config, fmt.Errorf("invalid charset: %v", err)
+	}
+
+	digits, symbols := config.injectedChars()
+	if pc.CustomSymbols != "" {
+		symbols = dedupeChars(pc.CustomSymbols)
+	}
+	config.Digits = removeChars(digits, pc.excludedChars())
+	config.Symbols = removeChars(symbols, pc.excludedChars())
+
+	if config.AddDigit && config.Digits == "" {
+		return config, fmt.Errorf("no digits are left to add to the passphrase")
+	}
+	if config.AddSymbol && config.Symbols == "" {
+		return config, fmt.Errorf("no symbols are left to add to the passphrase")
+	}
+	return config, nil
+}
+
+// GeneratePassphrase creates a passphrase of the given number of words using the default Generator,
+// which reads its randomness from crypto/rand.Reader.
+func GeneratePassphrase(words int, config PassphraseConfig) (string, error) {
+	return defaultGenerator.GeneratePassphrase(words, config)
+}
+
+// GeneratePassphrase creates a passphrase of the given number of words drawn from the Wordlist.
+// It returns the generated passphrase or an error if the word count is invalid or random number generation fails.
+func (g *Generator) GeneratePassphrase(words int, config PassphraseConfig) (string, error) {
+	// Return an error if the word count is invalid.
+	if words <= 0 {
+		return "", fmt.Errorf("word count must be greater than 0")
+	}
+
+	// Pick the words uniformly from the wordlist.
+	list := make([]string, words)
+	for i := range list {
+		idx, err := g.randomIndex(len(Wordlist))
+		if err != nil {
+			return "", err
+		}
+
+		list[i] = Wordlist[idx]
+		if config.Capitalize {
+			list[i] = strings.ToUpper(list[i][:1]) + list[i][1:]
+		}
+	}
+
+	digits, symbols := config.injectedChars()
+
+	// Inject a digit into a random word, if requested.
+	if config.AddDigit {
+		if err := g.appendRandomChar(list, digits); err != nil {
+			return "", err
+		}
+	}
+
+	// Inject a symbol into a random word, if requested.
+	if config.AddSymbol {
+		if err := g.appendRandomChar(list, symbols); err != nil {
+			return "", err
+		}
+	}
+
+	return strings.Join(list, config.Separator), nil
+}
+
+// PassphraseGenerator generates passphrases of a fixed number of words.
+type PassphraseGenerator struct {
+	g       *Generator
+	words   int
+	config  PassphraseConfig
+	entropy float64
+}
+
+// newPassphraseGenerator returns a PassphraseGenerator or an error if the word count is invalid.
+func (g *Generator) newPassphraseGenerator(words int, config PassphraseConfig) (*PassphraseGenerator, error) {
+	entropy, err := PassphraseEntropy(words, config)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating entropy: %v", err)
+	}
+	return &PassphraseGenerator{g: g, words: words, config: config, entropy: entropy}, nil
+}
+
+// Generate returns a new random passphrase.
+func (p *PassphraseGenerator) Generate() (string, error) {
+	passphrase, err := p.g.GeneratePassphrase(p.words, p.config)
+	if err != nil {
+		return "", fmt.Errorf("error generating passphrase: %v", err)
+	}
+	return passphrase, nil
+}
+
+// Entropy returns the entropy of the passphrases as calculated by PassphraseEntropy.
+func (p *PassphraseGenerator) Entropy() float64 {
+	return p.entropy
+}
+
+// PassphraseEntropy returns the entropy (in bits) of a passphrase with the given number of words.
+// Every word contributes log2(len(Wordlist)) bits, every injected character adds the entropy
+// of the character itself and of the word it was appended to.
+func PassphraseEntropy(words int, config PassphraseConfig) (float64, error) {
+	entropy, err := CalculateEntropy(len(Wordlist), words)
+	if err != nil {
+		return 0, err
+	}
+
+	digits, symbols := config.injectedChars()
+
+	if config.AddDigit {
+		entropy += math.Log2(float64(utf8.RuneCountInString(digits))) + math.Log2(float64(words))
+	}
+
+	if config.AddSymbol {
+		entropy += math.Log2(float64(utf8.RuneCountInString(symbols))) + math.Log2(float64(words))
+	}
+
+	return entropy, nil
+}
+
+// PassphraseLength returns the lengths in characters of the shortest and the longest passphrases
+// of the given number of words, made of the shortest or longest words of the Wordlist, the
+// separators and the injected characters.
+func PassphraseLength(words int, config PassphraseConfig) (shortest, longest int) {
+	if words <= 0 {
+		return 0, 0
+	}
+
+	shortestWord, longestWord := wordLengths()
+	rest := (words - 1) * utf8.RuneCountInString(config.Separator)
+	if config.AddDigit {
+		rest++
+	}
+	if config.AddSymbol {
+		rest++
+	}
+	return words*shortestWord + rest, words*longestWord + rest
+}
+
+// wordLengths returns the lengths in characters of the shortest and the longest word of the Wordlist.
+var wordLengths = sync.OnceValues(func() (int, int) {
+	shortest, longest := math.MaxInt, 0
+	for _, word := range Wordlist {
+		n := utf8.RuneCountInString(word)
+		shortest = min(shortest, n)
+		longest = max(longest, n)
+	}
+	return shortest, longest
+})
+
+// appendRandomChar appends a random character of the set to a random word of the list.
+func (g *Generator) appendRandomChar(list []string, set string) error {
+	word, err := g.randomIndex(len(list))
+	if err != nil {
+		return err
+	}
+
+	// The set may contain multibyte characters, such as custom symbols.
+	chars := []rune(set)
+	char, err := g.randomIndex(len(chars))
+	if err != nil {
+		return err
+	}
+
+	list[word] += string(chars[char])
+	return nil
+}
@@ -0,0 +1,261 @@
+package gofee
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// TestGeneratePassphrase tests the GeneratePassphrase function for various passphrase configurations.
+func TestGeneratePassphrase(t *testing.T) {
+	type args struct {
+		words  int
+		config PassphraseConfig
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "Plain words",
+			args: args{
+				words:  6,
+				config: PassphraseConfig{Separator: " "},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Capitalized words",
+			args: args{
+				words:  4,
+				config: PassphraseConfig{Separator: " ", Capitalize: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Digit and symbol injection",
+			args: args{
+				words:  5,
+				config: PassphraseConfig{Separator: " ", AddDigit: true, AddSymbol: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid word count",
+			args: args{
+				words:  0,
+				config: PassphraseConfig{Separator: " "},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GeneratePassphrase(tt.args.words, tt.args.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GeneratePassphrase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			// Check that the passphrase consists of the requested number of words.
+			words := strings.Split(got, tt.args.config.Separator)
+			if len(words) != tt.args.words {
+				t.Fatalf("GeneratePassphrase() = %q, want %d words", got, tt.args.words)
+			}
+
+			// Count the injected characters and verify every word is part of the wordlist.
+			var digits, symbols int
+			for _, word := range words {
+				word = strings.TrimRightFunc(word, func(r rune) bool {
+					switch {
+					case Contains(Digits, r):
+						digits++
+					case Contains(Symbols, r):
+						symbols++
+					default:
+						return false
+					}
+					return true
+				})
+
+				if tt.args.config.Capitalize {
+					if !unicode.IsUpper(rune(word[0])) {
+						t.Errorf("GeneratePassphrase() word %q is not capitalized", word)
+					}
+					word = strings.ToLower(word)
+				}
+
+				if !isWord(word) {
+					t.Errorf("GeneratePassphrase() word %q is not in the wordlist", word)
+				}
+			}
+
+			if want := boolToInt(tt.args.config.AddDigit); digits != want {
+				t.Errorf("GeneratePassphrase() = %q, has %d digits, want %d", got, digits, want)
+			}
+			if want := boolToInt(tt.args.config.AddSymbol); symbols != want {
+				t.Errorf("GeneratePassphrase() = %q, has %d symbols, want %d", got, symbols, want)
+			}
+		})
+	}
+}
+
+// TestPassphraseEntropy tests that every word contributes log2 of the wordlist size.
+func TestPassphraseEntropy(t *testing.T) {
+	tests := []struct {
+		name    string
+		words   int
+		config  PassphraseConfig
+		want    float64
+		wantErr bool
+	}{
+		{
+			name:  "Six words",
+			words: 6,
+			want:  6 * math.Log2(7776),
+		},
+		{
+			name:   "Digit and symbol injection",
+			words:  4,
+			config: PassphraseConfig{AddDigit: true, AddSymbol: true},
+			want:   4*math.Log2(7776) + math.Log2(10) + math.Log2(28) + 2*math.Log2(4),
+		},
+		{
+			name:   "Custom injected characters",
+			words:  4,
+			config: PassphraseConfig{AddDigit: true, AddSymbol: true, Digits: "34", Symbols: "€£!"},
+			want:   4*math.Log2(7776) + math.Log2(2) + math.Log2(3) + 2*math.Log2(4),
+		},
+		{
+			name:    "Invalid word count",
+			words:   0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PassphraseEntropy(tt.words, tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PassphraseEntropy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("PassphraseEntropy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPassphraseInjectedChars checks that the custom symbols and the excluded characters of a
+// memorable PasswordConfig apply to the appended digit and symbol.
+func TestPassphraseInjectedChars(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  PasswordConfig
+		allowed string
+		wantErr bool
+	}{
+		{"Custom symbols", PasswordConfig{CustomSymbols: "€£"}, Digits + "€£", false},
+		{"Excluded characters", PasswordConfig{ExcludeChars: "0123456!#$%&"}, "789" + removeChars(Symbols, "!#$%&"), false},
+		{"No ambiguous characters", PasswordConfig{ExcludeAmbiguous: true}, removeChars(Digits+Symbols, Ambiguous), false},
+		{"No digits left", PasswordConfig{ExcludeChars: Digits}, "", true},
+		{"No symbols left", PasswordConfig{CustomSymbols: "!?", ExcludeChars: "!?"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Type = "memorable"
+			config.Passphrase = PassphraseConfig{Separator: " ", AddDigit: true, AddSymbol: true}
+
+			pg, err := New(3, config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			for i := 0; i < 200; i++ {
+				password, err := pg.Generate()
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				// The characters are appended to the words, some of which contain hyphens.
+				for _, word := range strings.Fields(password) {
+					appended := strings.TrimLeft(word, Lowers+"-")
+					if !isWord(strings.TrimSuffix(word, appended)) {
+						// A hyphen was appended to the word.
+						appended = word[strings.LastIndexAny(word, Lowers)+1:]
+					}
+					if strings.Trim(appended, tt.allowed) != "" {
+						t.Fatalf("Generate() = %q, appends %q", password, appended)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestPassphraseLength checks the lengths of the shortest and longest passphrases, made of words
+// of 3 to 9 letters.
+func TestPassphraseLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		words    int
+		config   PassphraseConfig
+		shortest int
+		longest  int
+	}{
+		{"One word", 1, PassphraseConfig{Separator: "-"}, 3, 9},
+		{"Separators", 4, PassphraseConfig{Separator: " - "}, 4*3 + 3*3, 4*9 + 3*3},
+		{"Injected characters", 6, PassphraseConfig{AddDigit: true, AddSymbol: true}, 6*3 + 2, 6*9 + 2},
+		{"No words", 0, PassphraseConfig{Separator: "-"}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortest, longest := PassphraseLength(tt.words, tt.config)
+			if shortest != tt.shortest || longest != tt.longest {
+				t.Errorf("PassphraseLength() = %d, %d, want %d, %d", shortest, longest, tt.shortest, tt.longest)
+			}
+		})
+	}
+
+	// Generated passphrases are within the lengths.
+	config := PassphraseConfig{Separator: "_", AddDigit: true}
+	shortest, longest := PassphraseLength(5, config)
+	for i := 0; i < 200; i++ {
+		passphrase, err := GeneratePassphrase(5, config)
+		if err != nil {
+			t.Fatalf("GeneratePassphrase() error = %v", err)
+		}
+		if n := len(passphrase); n < shortest || n > longest {
+			t.Fatalf("GeneratePassphrase() = %q, want %d to %d characters", passphrase, shortest, longest)
+		}
+	}
+}
+
+// isWord reports whether the word is part of the Wordlist.
+func isWord(word string) bool {
+	for _, w := range Wordlist {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// boolToInt converts a bool into 1 or 0.
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,195 @@
+package gofee
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Vowels and consonants of the patterns.
+const (
+	Vowels     = "aeiou"
+	Consonants = "bcdfghjklmnpqrstvwxyz"
+)
+
+// maxPatternLength is the maximum number of positions of a pattern, after expanding the repetitions.
+const maxPatternLength = 1024
+
+// patternTokens maps the tokens of a pattern to the characters they are drawn from.
+// Symbols are looked up in the config, as they can be customized.
+var patternTokens = map[rune]string{
+	'l': Lowers,
+	'u': Uppers,
+	'a': Lowers + Uppers,
+	'c': Consonants,
+	'C': strings.ToUpper(Consonants),
+	'v': Vowels,
+	'V': strings.ToUpper(Vowels),
+	'd': Digits,
+	'9': Digits,
+	'h': "0123456789abcdef",
+	'H': "0123456789ABCDEF",
+	'x': Lowers + Uppers + Digits,
+	's': "", // The symbols of the config.
+	'*': "", // All characters of the config.
+}
+
+// patternPosition is a single position of a pattern, which is either drawn from chars or literal.
+type patternPosition struct {
+	chars   []rune
+	literal string
+}
+
+// parsePattern parses a pattern into its positions, using the symbols and exclusions of the config.
+//
+// The tokens of a pattern are:
+//
+//	l  lowercase letter       u  uppercase letter       a  letter
+//	c  lowercase consonant    C  uppercase consonant    x  letter or digit
+//	v  lowercase vowel        V  uppercase vowel        h  lowercase hex digit
+//	d  digit (also 9)         s  symbol                 H  uppercase hex digit
+//	*  letter, digit or symbol
+//
+// A token followed by {n} is repeated n times, as in "d{4}". A backslash escapes the next
+// character, which is then taken literally, as in "\d". Other letters and digits are reserved for
+// future tokens and rejected; all remaining characters, such as "-" or ".", are literals.
+func (config PasswordConfig) parsePattern() ([]patternPosition, error) {
+	symbols := Symbols
+	if config.CustomSymbols != "" {
+		symbols = dedupeChars(config.CustomSymbols)
+	}
+	excluded := config.excludedChars()
+
+	var positions []patternPosition
+	runes := []rune(config.Pattern)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		var position patternPosition
+
+		switch chars, ok := patternTokens[r]; {
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("pattern ends with an escape")
+			}
+			i++
+			position.literal = string(runes[i])
+		case ok:
+			switch r {
+			case 's':
+				chars = symbols
+			case '*':
+				chars = Lowers + Uppers + Digits + symbols
+			}
+			position.chars = []rune(removeChars(dedupeChars(chars), excluded))
+			if len(position.chars) == 0 {
+				return nil, fmt.Errorf("no characters left for %q at position %d", r, i+1)
+			}
+		case r == '{' || r == '}':
+			return nil, fmt.Errorf("unexpected %q at position %d", r, i+1)
+		case r < utf8.RuneSelf && (isASCIILetter(r) || ('0' <= r && r <= '9')):
+			return nil, fmt.Errorf("unknown token %q at position %d (escape it as \\%c to use it literally)", r, i+1, r)
+		default:
+			position.literal = string(r)
+		}
+
+		// Read the repetition count following the token.
+		count := 1
+		if i+1 < len(runes) && runes[i+1] == '{' {
+			end := i + 2
+			for end < len(runes) && runes[end] != '}' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unclosed repetition at position %d", i+2)
+			}
+			n, err := strconv.Atoi(string(runes[i+2 : end]))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid repetition count %q at position %d", string(runes[i+2:end]), i+2)
+			}
+			count = n
+			i = end
+		}
+
+		if len(positions)+count > maxPatternLength {
+			return nil, fmt.Errorf("pattern is longer than %d characters", maxPatternLength)
+		}
+		for j := 0; j < count; j++ {
+			positions = append(positions, position)
+		}
+	}
+
+	if len(positions) == 0 {
+		return nil, fmt.Errorf("pattern is empty")
+	}
+	return positions, nil
+}
+
+// isASCIILetter reports whether r is an ASCII letter.
+func isASCIILetter(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
+
+// PatternGenerator generates passwords of a fixed shape, where every position
+// is drawn from its own character class or is a literal.
+type PatternGenerator struct {
+	g         *Generator
+	positions []patternPosition
+}
+
+// newPatternGenerator returns a PatternGenerator for the pattern of the config or an error if the
+// pattern is invalid.
+func (g *Generator) newPatternGenerator(config PasswordConfig) (*PatternGenerator, error) {
+	if config.CustomCharset != "" {
+		return nil, fmt.Errorf("a pattern cannot be combined with a custom charset")
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid charset: %v", err)
+	}
+
+	positions, err := config.parsePattern()
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %v", err)
+	}
+	return &PatternGenerator{g: g, positions: positions}, nil
+}
+
+// Generate returns a new random password of the pattern.
+func (p *PatternGenerator) Generate() (string, error) {
+	var builder strings.Builder
+	for _, position := range p.positions {
+		if position.literal != "" {
+			builder.WriteString(position.literal)
+			continue
+		}
+
+		num, err := p.g.randomIndex(len(position.chars))
+		if err != nil {
+			return "", fmt.Errorf("error mapping number to pattern: %v", err)
+		}
+		builder.WriteRune(position.chars[num])
+	}
+	return builder.String(), nil
+}
+
+// Entropy returns the sum of log2(class size) over all positions. Literals add no entropy.
+func (p *PatternGenerator) Entropy() float64 {
+	var entropy float64
+	for _, position := range p.positions {
+		if position.literal == "" {
+			entropy += math.Log2(float64(len(position.chars)))
+		}
+	}
+	return entropy
+}
+
+// Charset returns all characters the passwords can consist of, including the literals.
+func (p *PatternGenerator) Charset() string {
+	var builder strings.Builder
+	for _, position := range p.positions {
+		builder.WriteString(string(position.chars))
+		builder.WriteString(position.literal)
+	}
+	return dedupeChars(builder.String())
+}
@@ -0,0 +1,127 @@
+package gofee
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestGeneratePattern checks that every position of a generated password matches its token.
+func TestGeneratePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  PasswordConfig
+		classes []string // The characters allowed at each position.
+	}{
+		{
+			name:    "Vendor shape",
+			config:  PasswordConfig{Pattern: "Cvccvc-99-ss"},
+			classes: []string{strings.ToUpper(Consonants), Vowels, Consonants, Consonants, Vowels, Consonants, "-", Digits, Digits, "-", Symbols, Symbols},
+		},
+		{
+			name:    "Repetition",
+			config:  PasswordConfig{Pattern: "u{2}d{4}s"},
+			classes: []string{Uppers, Uppers, Digits, Digits, Digits, Digits, Symbols},
+		},
+		{
+			name:    "Escapes and literals",
+			config:  PasswordConfig{Pattern: `\d\{h\}.€`},
+			classes: []string{"d", "{", "0123456789abcdef", "}", ".", "€"},
+		},
+		{
+			name:    "Repeated literal",
+			config:  PasswordConfig{Pattern: "#{3}V"},
+			classes: []string{"#", "#", "#", "AEIOU"},
+		},
+		{
+			name:    "Custom symbols and exclusions",
+			config:  PasswordConfig{Pattern: "sa*", CustomSymbols: "!?", ExcludeChars: "?", ExcludeAmbiguous: true},
+			classes: []string{"!", removeChars(Lowers+Uppers, Ambiguous), removeChars(Lowers+Uppers+Digits+"!", Ambiguous)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				// The length is ignored for patterns.
+				password, err := Generate(1, tt.config)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+
+				runes := []rune(password)
+				if len(runes) != len(tt.classes) {
+					t.Fatalf("Generate() = %q, want %d characters", password, len(tt.classes))
+				}
+				for j, c := range runes {
+					if !strings.ContainsRune(tt.classes[j], c) {
+						t.Errorf("Generate() = %q, character %d %q not in %q", password, j, c, tt.classes[j])
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestPatternEntropy checks that the entropy is the sum over the positions.
+func TestPatternEntropy(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    float64
+	}{
+		{"Cvccvc-99-ss", 4*math.Log2(21) + 2*math.Log2(5) + 2*math.Log2(10) + 2*math.Log2(float64(len(Symbols)))},
+		{"d{6}", 6 * math.Log2(10)},
+		{"h{32}", 128},
+		{`\d-\s`, 0},
+	}
+
+	for _, tt := range tests {
+		result, err := GenerateResult(1, PasswordConfig{Pattern: tt.pattern})
+		if err != nil {
+			t.Fatalf("GenerateResult(%q) error = %v", tt.pattern, err)
+		}
+		if math.Abs(result.Entropy-tt.want) > 1e-9 {
+			t.Errorf("GenerateResult(%q) entropy = %v, want %v", tt.pattern, result.Entropy, tt.want)
+		}
+	}
+}
+
+// TestPatternCharset checks that the charset of a pattern contains its classes and literals.
+func TestPatternCharset(t *testing.T) {
+	result, err := GenerateResult(1, PasswordConfig{Pattern: "v-9"})
+	if err != nil {
+		t.Fatalf("GenerateResult() error = %v", err)
+	}
+	if result.Charset != Vowels+"-"+Digits {
+		t.Errorf("GenerateResult() charset = %q, want %q", result.Charset, Vowels+"-"+Digits)
+	}
+}
+
+// TestPatternErrors checks that invalid patterns are rejected.
+func TestPatternErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  PasswordConfig
+		wantErr string
+	}{
+		{"Unknown letter", PasswordConfig{Pattern: "lbl"}, `unknown token 'b' at position 2`},
+		{"Unknown digit", PasswordConfig{Pattern: "1"}, `unknown token '1'`},
+		{"Trailing escape", PasswordConfig{Pattern: `d\`}, "ends with an escape"},
+		{"Unclosed repetition", PasswordConfig{Pattern: "d{4"}, "unclosed repetition"},
+		{"Invalid count", PasswordConfig{Pattern: "d{x}"}, "invalid repetition count"},
+		{"Zero count", PasswordConfig{Pattern: "d{0}"}, "invalid repetition count"},
+		{"Repetition without token", PasswordConfig{Pattern: "{2}"}, "unexpected '{'"},
+		{"Too long", PasswordConfig{Pattern: "d{2000}"}, "longer than"},
+		{"Empty class", PasswordConfig{Pattern: "v", ExcludeChars: Vowels}, "no characters left for 'v'"},
+		{"Custom charset", PasswordConfig{Pattern: "d", CustomCharset: "abc"}, "custom charset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(1, tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
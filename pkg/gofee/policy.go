@@ -0,0 +1,300 @@
+package gofee
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy describes the password rules of a target system, such as "at most 20 characters,
+// no &, starting with a letter, at least 2 digits". Policies are stored as YAML or JSON files:
+//
+//	name: legacy-erp
+//	description: Passwords of the old ERP system
+//	max_length: 20
+//	exclude: "&"
+//	first: [letters]
+//	min_digits: 2
+type Policy struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+
+	// Type is the type of the passwords (pin, memorable or pronounceable), empty for charset passwords.
+	Type string `yaml:"type" json:"type"`
+	// Length is the length of the passwords, or the number of words of memorable ones. Without
+	// it, the default length clamped to MinLength and MaxLength is used. MinLength and MaxLength
+	// count characters. For memorable passwords, every passphrase of the number of words must
+	// fit into them, no matter which words are drawn.
+	Length    int `yaml:"length" json:"length"`
+	MinLength int `yaml:"min_length" json:"min_length"`
+	MaxLength int `yaml:"max_length" json:"max_length"`
+
+	// The options of memorable passwords. The separator is DefaultSeparator if not set.
+	Separator  *string `yaml:"separator" json:"separator"`
+	Capitalize bool    `yaml:"capitalize" json:"capitalize"`
+	AddDigit   bool    `yaml:"add_digit" json:"add_digit"`
+	AddSymbol  bool    `yaml:"add_symbol" json:"add_symbol"`
+
+	// The included character classes, all of them if not set.
+	Lowers  *bool `yaml:"lowers" json:"lowers"`
+	Uppers  *bool `yaml:"uppers" json:"uppers"`
+	Digits  *bool `yaml:"digits" json:"digits"`
+	Symbols *bool `yaml:"symbols" json:"symbols"`
+
+	// SymbolSet replaces the default symbols, Charset replaces all classes.
+	SymbolSet string `yaml:"symbol_set" json:"symbol_set"`
+	Charset   string `yaml:"charset" json:"charset"`
+	// Exclude lists the characters the system does not accept.
+	Exclude     string `yaml:"exclude" json:"exclude"`
+	NoAmbiguous bool   `yaml:"no_ambiguous" json:"no_ambiguous"`
+
+	RequireAll bool `yaml:"require_all" json:"require_all"`
+	MinLowers  int  `yaml:"min_lowers" json:"min_lowers"`
+	MinUppers  int  `yaml:"min_uppers" json:"min_uppers"`
+	MinDigits  int  `yaml:"min_digits" json:"min_digits"`
+	MinSymbols int  `yaml:"min_symbols" json:"min_symbols"`
+
+	// First lists the classes the first character is drawn from: lowers, uppers, letters, digits or symbols.
+	First []string `yaml:"first" json:"first"`
+
+	// Pattern defines the shape of the passwords instead of the length and classes.
+	Pattern string `yaml:"pattern" json:"pattern"`
+}
+
+// policyExtensions are the file extensions of policies, in the order they are looked up.
+var policyExtensions = []string{".yaml", ".yml", ".json"}
+
+// PolicyDir returns the directory of the named policies, $XDG_CONFIG_HOME/gofee/policies on Linux.
+func PolicyDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gofee", "policies"), nil
+}
+
+// FindPolicy returns the path of the policy with the given name in dir. Names containing a path
+// separator or ending with a policy extension are paths and returned as they are.
+func FindPolicy(dir, name string) (string, error) {
+	if strings.ContainsRune(name, filepath.Separator) || strings.ContainsRune(name, '/') || isPolicyFile(name) {
+		return name, nil
+	}
+
+	for _, ext := range policyExtensions {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("policy %q not found in %s", name, dir)
+}
+
+// isPolicyFile reports whether the name has the extension of a policy file.
+func isPolicyFile(name string) bool {
+	for _, ext := range policyExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadPolicy reads a policy from a YAML or JSON file. Unknown fields are rejected, so
+// misspelled rules are not silently ignored. Policies without a name are named after the file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := ParsePolicy(data, strings.HasSuffix(path, ".json"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %v", path, err)
+	}
+	if policy.Name == "" {
+		policy.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return policy, nil
+}
+
+// ParsePolicy parses a policy in the JSON or YAML format.
+func ParsePolicy(data []byte, isJSON bool) (*Policy, error) {
+	policy := &Policy{}
+
+	if isJSON {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(policy); err != nil {
+			return nil, err
+		}
+		return policy, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Config maps the policy onto the length and PasswordConfig of the passwords.
+// defaultLength is used if the policy does not fix the length, clamped to its limits.
+// It returns an error if the rules contradict each other.
+func (p *Policy) Config(defaultLength int) (int, PasswordConfig, error) {
+	if p.MinLength < 0 || p.MaxLength < 0 || p.Length < 0 {
+		return 0, PasswordConfig{}, fmt.Errorf("lengths must not be negative")
+	}
+	if p.MaxLength > 0 && p.MinLength > p.MaxLength {
+		return 0, PasswordConfig{}, fmt.Errorf("min_length %d is greater than max_length %d", p.MinLength, p.MaxLength)
+	}
+
+	passphrase := PassphraseConfig{
+		Separator:  DefaultSeparator,
+		Capitalize: p.Capitalize,
+		AddDigit:   p.AddDigit,
+		AddSymbol:  p.AddSymbol,
+	}
+	if p.Separator != nil {
+		passphrase.Separator = *p.Separator
+	}
+
+	length := p.Length
+	switch {
+	case p.Type == "memorable":
+		var err error
+		if length, err = p.passphraseWords(defaultLength, passphrase); err != nil {
+			return 0, PasswordConfig{}, err
+		}
+	case length > 0 && length < p.MinLength:
+		return 0, PasswordConfig{}, fmt.Errorf("length %d is shorter than min_length %d", length, p.MinLength)
+	case length > 0 && p.MaxLength > 0 && length > p.MaxLength:
+		return 0, PasswordConfig{}, fmt.Errorf("length %d is longer than max_length %d", length, p.MaxLength)
+	case length == 0:
+		length = max(defaultLength, p.MinLength)
+		if p.MaxLength > 0 {
+			length = min(length, p.MaxLength)
+		}
+	}
+
+	included := func(b *bool) bool { return b == nil || *b }
+	config := PasswordConfig{
+		IncludeLowers:    included(p.Lowers),
+		IncludeUppers:    included(p.Uppers),
+		IncludeDigits:    included(p.Digits),
+		IncludeSymbols:   included(p.Symbols),
+		Type:             p.Type,
+		RequireAll:       p.RequireAll,
+		MinLowers:        p.MinLowers,
+		MinUppers:        p.MinUppers,
+		MinDigits:        p.MinDigits,
+		MinSymbols:       p.MinSymbols,
+		CustomCharset:    p.Charset,
+		CustomSymbols:    p.SymbolSet,
+		ExcludeChars:     p.Exclude,
+		ExcludeAmbiguous: p.NoAmbiguous,
+		Pattern:          p.Pattern,
+	}
+
+	if p.Type == "memorable" {
+		config.Passphrase = passphrase
+	}
+
+	for _, name := range p.First {
+		chars, err := p.firstClass(name)
+		if err != nil {
+			return 0, PasswordConfig{}, err
+		}
+		config.FirstChars += chars
+	}
+
+	return length, config, nil
+}
+
+// passphraseWords returns the number of words of memorable passwords: the fixed length of the
+// policy, or defaultWords clamped so that passphrases of any words fit into the length limits.
+func (p *Policy) passphraseWords(defaultWords int, config PassphraseConfig) (int, error) {
+	fits := func(words int) error {
+		shortest, longest := PassphraseLength(words, config)
+		if shortest < p.MinLength {
+			return fmt.Errorf("passphrases of %d words can have %d characters, which is less than min_length %d", words, shortest, p.MinLength)
+		}
+		if p.MaxLength > 0 && longest > p.MaxLength {
+			return fmt.Errorf("passphrases of %d words can have %d characters, which is more than max_length %d", words, longest, p.MaxLength)
+		}
+		return nil
+	}
+
+	if p.Length > 0 {
+		return p.Length, fits(p.Length)
+	}
+
+	words := defaultWords
+	for p.MaxLength > 0 && words > 1 {
+		if _, longest := PassphraseLength(words, config); longest <= p.MaxLength {
+			break
+		}
+		words--
+	}
+	for {
+		if shortest, _ := PassphraseLength(words, config); shortest >= p.MinLength {
+			break
+		}
+		words++
+	}
+	return words, fits(words)
+}
+
+// firstClass returns the characters of a class name used by First.
+func (p *Policy) firstClass(name string) (string, error) {
+	switch name {
+	case "lowers":
+		return Lowers, nil
+	case "uppers":
+		return Uppers, nil
+	case "letters":
+		return Lowers + Uppers, nil
+	case "digits":
+		return Digits, nil
+	case "symbols":
+		if p.SymbolSet != "" {
+			return p.SymbolSet, nil
+		}
+		return Symbols, nil
+	}
+	return "", fmt.Errorf("unknown class %q in first (supported: lowers, uppers, letters, digits, symbols)", name)
+}
+
+// Validate checks that passwords satisfying the policy can be generated and returns their entropy.
+// Passwords longer than max_length, for example from a pattern, are rejected as well.
+func (p *Policy) Validate(defaultLength int) (float64, error) {
+	length, config, err := p.Config(defaultLength)
+	if err != nil {
+		return 0, err
+	}
+
+	pg, err := New(length, config)
+	if err != nil {
+		return 0, err
+	}
+
+	// The length of patterns and passphrases is only known from a generated password.
+	password, err := pg.Generate()
+	if err != nil {
+		return 0, err
+	}
+	if n := len([]rune(password)); n < p.MinLength {
+		return 0, fmt.Errorf("passwords have %d characters, which is less than min_length %d", n, p.MinLength)
+	} else if p.MaxLength > 0 && n > p.MaxLength {
+		return 0, fmt.Errorf("passwords have %d characters, which is more than max_length %d", n, p.MaxLength)
+	}
+
+	return pg.Entropy(), nil
+}
@@ -0,0 +1,210 @@
+package gofee
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParsePolicy checks that YAML and JSON policies are parsed into the same rules.
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		isJSON bool
+	}{
+		{
+			name: "YAML",
+			data: "name: legacy-erp\nmax_length: 20\nsymbols: false\nexclude: \"&\"\nfirst: [letters]\nmin_digits: 2\n",
+		},
+		{
+			name:   "JSON",
+			data:   `{"name": "legacy-erp", "max_length": 20, "symbols": false, "exclude": "&", "first": ["letters"], "min_digits": 2}`,
+			isJSON: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParsePolicy([]byte(tt.data), tt.isJSON)
+			if err != nil {
+				t.Fatalf("ParsePolicy() error = %v", err)
+			}
+
+			if policy.Name != "legacy-erp" || policy.MaxLength != 20 || policy.Exclude != "&" || policy.MinDigits != 2 {
+				t.Errorf("ParsePolicy() = %+v, want the rules of the data", policy)
+			}
+			if policy.Symbols == nil || *policy.Symbols || policy.Lowers != nil {
+				t.Errorf("ParsePolicy() classes = %v, %v, want only symbols set to false", policy.Lowers, policy.Symbols)
+			}
+			if len(policy.First) != 1 || policy.First[0] != "letters" {
+				t.Errorf("ParsePolicy() first = %v, want [letters]", policy.First)
+			}
+		})
+	}
+}
+
+// TestParsePolicyErrors checks that misspelled rules are rejected.
+func TestParsePolicyErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		isJSON bool
+	}{
+		{"Unknown YAML field", "max_lenght: 20\n", false},
+		{"Unknown JSON field", `{"max_lenght": 20}`, true},
+		{"Invalid YAML", "max_length: [20\n", false},
+		{"Invalid type", "max_length: twenty\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParsePolicy([]byte(tt.data), tt.isJSON); err == nil {
+				t.Errorf("ParsePolicy() returned no error")
+			}
+		})
+	}
+}
+
+// TestFindPolicy checks that names are looked up in the directory and paths are kept.
+func TestFindPolicy(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "erp.yml"), []byte("max_length: 20\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := FindPolicy(dir, "erp")
+	if err != nil || path != filepath.Join(dir, "erp.yml") {
+		t.Errorf("FindPolicy() = %q, %v, want %q", path, err, filepath.Join(dir, "erp.yml"))
+	}
+	if path, err := FindPolicy(dir, "other/erp.json"); err != nil || path != "other/erp.json" {
+		t.Errorf("FindPolicy() = %q, %v, want the path", path, err)
+	}
+	if _, err := FindPolicy(dir, "missing"); err == nil {
+		t.Errorf("FindPolicy() returned no error for a missing policy")
+	}
+
+	// Policies without a name are named after their file.
+	policy, err := LoadPolicy(filepath.Join(dir, "erp.yml"))
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+	if policy.Name != "erp" {
+		t.Errorf("LoadPolicy() name = %q, want %q", policy.Name, "erp")
+	}
+}
+
+// TestPolicyConfig checks the mapping of policies onto the length and PasswordConfig.
+func TestPolicyConfig(t *testing.T) {
+	no := false
+	space := " "
+
+	tests := []struct {
+		name       string
+		policy     Policy
+		wantLength int
+		wantConfig PasswordConfig
+	}{
+		{
+			name:       "Defaults",
+			policy:     Policy{},
+			wantLength: 16,
+			wantConfig: PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true},
+		},
+		{
+			name:       "Clamped to the maximum",
+			policy:     Policy{MaxLength: 12, Symbols: &no, Exclude: "&"},
+			wantLength: 12,
+			wantConfig: PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, ExcludeChars: "&"},
+		},
+		{
+			name:       "Clamped to the minimum",
+			policy:     Policy{MinLength: 20, RequireAll: true},
+			wantLength: 20,
+			wantConfig: PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true, RequireAll: true},
+		},
+		{
+			name:       "Fixed length and first characters",
+			policy:     Policy{Length: 10, MaxLength: 10, First: []string{"uppers", "digits"}, MinDigits: 2},
+			wantLength: 10,
+			wantConfig: PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true, MinDigits: 2, FirstChars: Uppers + Digits},
+		},
+		{
+			// The limits count characters: 6 words of up to 9 letters and 5 separators fit into 64.
+			name:       "Memorable",
+			policy:     Policy{Type: "memorable", MinLength: 20, MaxLength: 64},
+			wantLength: 6,
+			wantConfig: PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true, Type: "memorable", Passphrase: PassphraseConfig{Separator: "-"}},
+		},
+		{
+			// 20 words of at least 3 letters, 19 separators and a digit are needed for 80 characters.
+			name:       "Memorable raised to the minimum",
+			policy:     Policy{Type: "memorable", MinLength: 80, Separator: &space, AddDigit: true, Capitalize: true},
+			wantLength: 20,
+			wantConfig: PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true, Type: "memorable", Passphrase: PassphraseConfig{Separator: " ", Capitalize: true, AddDigit: true}},
+		},
+		{
+			name:       "Custom symbols",
+			policy:     Policy{SymbolSet: "!#", First: []string{"symbols"}},
+			wantLength: 16,
+			wantConfig: PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true, CustomSymbols: "!#", FirstChars: "!#"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			length, config, err := tt.policy.Config(16)
+			if err != nil {
+				t.Fatalf("Config() error = %v", err)
+			}
+			if length != tt.wantLength {
+				t.Errorf("Config() length = %d, want %d", length, tt.wantLength)
+			}
+			if config != tt.wantConfig {
+				t.Errorf("Config() = %+v, want %+v", config, tt.wantConfig)
+			}
+		})
+	}
+}
+
+// TestPolicyValidate checks that satisfiable policies report their entropy and others an error.
+func TestPolicyValidate(t *testing.T) {
+	no := false
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr string
+	}{
+		{"Valid", Policy{MaxLength: 20, Exclude: "&", First: []string{"letters"}, MinDigits: 2}, ""},
+		{"Valid pattern", Policy{Pattern: "Cvccvc-99", MaxLength: 10}, ""},
+		{"Contradicting lengths", Policy{MinLength: 20, MaxLength: 10}, "greater than max_length"},
+		{"Length too long", Policy{Length: 30, MaxLength: 20}, "longer than max_length"},
+		{"Length too short", Policy{Length: 6, MinLength: 8}, "shorter than min_length"},
+		{"Unknown first class", Policy{First: []string{"vowels"}}, "unknown class"},
+		{"First class excluded", Policy{Digits: &no, First: []string{"digits"}}, "first characters"},
+		{"Too many required", Policy{MaxLength: 4, MinDigits: 3, MinSymbols: 3}, "invalid constraints"},
+		{"Pattern too long", Policy{Pattern: "d{12}", MaxLength: 10}, "more than max_length"},
+		{"Valid memorable", Policy{Type: "memorable", Length: 4, MinLength: 12}, ""},
+		{"Memorable too long", Policy{Type: "memorable", Length: 8, MaxLength: 20}, "more than max_length"},
+		{"Memorable too short", Policy{Type: "memorable", Length: 2, MinLength: 30}, "less than min_length"},
+		// No number of words fits between 30 and 35 characters: 7 words can be too long, 6 too short.
+		{"Memorable limits too close", Policy{Type: "memorable", MinLength: 30, MaxLength: 35}, "max_length"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entropy, err := tt.policy.Validate(16)
+			if tt.wantErr == "" {
+				if err != nil || entropy <= 0 {
+					t.Errorf("Validate() = %v, %v, want a positive entropy", entropy, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package gofee
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Units of pronounceable passwords. A password alternates between consonant and vowel units,
+// so every run of consonants or vowels is exactly one unit and no two sequences of units produce
+// the same password. So the probability of a password is the probability of its walk.
+var (
+	consonantUnits = []string{
+		"b", "c", "d", "f", "g", "h", "j", "k", "l", "m", "n", "p", "r", "s", "t", "v", "w", "x", "y", "z",
+		"ch", "sh", "th", "ph", "wh", "tr", "st", "br", "gr", "pl",
+	}
+	vowelUnits = []string{
+		"a", "e", "i", "o", "u",
+		"ai", "ea", "ee", "ie", "oo", "ou",
+	}
+)
+
+// PronounceableGenerator generates pronounceable passwords of alternating consonant and vowel
+// units, similar to pwgen. A number of letters can be uppercased and a number of digits can be
+// sprinkled in at random positions.
+type PronounceableGenerator struct {
+	g          *Generator
+	length     int
+	uppercase  int
+	digits     int
+	units      [2][]string // The consonant and the vowel units.
+	digitChars string
+	entropy    float64
+}
+
+// newPronounceableGenerator returns a PronounceableGenerator for the config. The number of uppercase
+// letters and digits are the minimum counts of the config, or 1 if RequireAll includes their class.
+// It returns an error if symbols are required or the counts do not fit into the password.
+func (g *Generator) newPronounceableGenerator(length int, config PasswordConfig) (*PronounceableGenerator, error) {
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid charset: %v", err)
+	}
+	if config.CustomCharset != "" {
+		return nil, fmt.Errorf("pronounceable passwords cannot be combined with a custom charset")
+	}
+	if config.MinSymbols > 0 || config.MinLowers > 0 {
+		return nil, fmt.Errorf("invalid constraints: pronounceable passwords only support minimum uppercase letters and digits")
+	}
+
+	count := func(name string, min int, included bool) (int, error) {
+		switch {
+		case min < 0:
+			return 0, fmt.Errorf("invalid constraints: minimum number of %s must not be negative", name)
+		case min > 0 && !included:
+			return 0, fmt.Errorf("invalid constraints: %s are required but not included", name)
+		case min == 0 && config.RequireAll && included:
+			return 1, nil
+		}
+		return min, nil
+	}
+	uppercase, err := count("uppercase letters", config.MinUppers, config.IncludeUppers)
+	if err != nil {
+		return nil, err
+	}
+	digits, err := count("digits", config.MinDigits, config.IncludeDigits)
+	if err != nil {
+		return nil, err
+	}
+	if uppercase+digits > length || digits >= length {
+		return nil, fmt.Errorf("invalid constraints: length %d is too short for %d uppercase letters and %d digits", length, uppercase, digits)
+	}
+
+	p := &PronounceableGenerator{
+		g:          g,
+		length:     length,
+		uppercase:  uppercase,
+		digits:     digits,
+		digitChars: removeChars(Digits, config.excludedChars()),
+	}
+	if digits > 0 && p.digitChars == "" {
+		return nil, fmt.Errorf("no digits left after removing the excluded characters")
+	}
+
+	// Units with excluded letters are never used. Letters that may be uppercased must not be
+	// excluded in uppercase either.
+	for i, units := range [][]string{consonantUnits, vowelUnits} {
+		for _, unit := range units {
+			if strings.ContainsAny(unit, config.excludedChars()) ||
+				(uppercase > 0 && strings.ContainsAny(strings.ToUpper(unit), config.excludedChars())) {
+				continue
+			}
+			p.units[i] = append(p.units[i], unit)
+		}
+		// Single letter units are needed to fill the last letter.
+		if len(fittingUnits(p.units[i], 1)) == 0 {
+			return nil, fmt.Errorf("no single consonants or vowels left after removing the excluded characters")
+		}
+	}
+
+	p.entropy = p.calculateEntropy()
+	return p, nil
+}
+
+// Generate returns a new random pronounceable password.
+func (p *PronounceableGenerator) Generate() (string, error) {
+	password, err := p.generate()
+	if err != nil {
+		return "", fmt.Errorf("error generating pronounceable password: %v", err)
+	}
+	return password, nil
+}
+
+// generate builds the letters unit by unit, uppercases some of them and inserts the digits.
+func (p *PronounceableGenerator) generate() (string, error) {
+	letters := p.length - p.digits
+
+	// Start with a consonant or a vowel unit, then alternate.
+	kind, err := p.g.randomIndex(2)
+	if err != nil {
+		return "", err
+	}
+
+	word := make([]byte, 0, letters)
+	for len(word) < letters {
+		units := fittingUnits(p.units[kind], letters-len(word))
+		i, err := p.g.randomIndex(len(units))
+		if err != nil {
+			return "", err
+		}
+		word = append(word, units[i]...)
+		kind = 1 - kind
+	}
+
+	// Uppercase random letters.
+	upper, err := p.g.randomPositions(letters, p.uppercase)
+	if err != nil {
+		return "", err
+	}
+	for i := range word {
+		if upper[i] {
+			word[i] = strings.ToUpper(string(word[i]))[0]
+		}
+	}
+
+	// Put random digits at random positions and the letters in order around them.
+	digitAt, err := p.g.randomPositions(p.length, p.digits)
+	if err != nil {
+		return "", err
+	}
+	ret := make([]byte, 0, p.length)
+	for i := 0; i < p.length; i++ {
+		if !digitAt[i] {
+			ret = append(ret, word[0])
+			word = word[1:]
+			continue
+		}
+		d, err := p.g.randomIndex(len(p.digitChars))
+		if err != nil {
+			return "", err
+		}
+		ret = append(ret, p.digitChars[d])
+	}
+
+	return string(ret), nil
+}
+
+// Entropy returns the min-entropy of the generated passwords, which is much lower than the
+// entropy of a random password of the same length and letters.
+func (p *PronounceableGenerator) Entropy() float64 {
+	return p.entropy
+}
+
+// Charset returns the characters the passwords can consist of.
+func (p *PronounceableGenerator) Charset() string {
+	letters := strings.Join(p.units[0], "") + strings.Join(p.units[1], "")
+	if p.uppercase > 0 {
+		letters += strings.ToUpper(letters)
+	}
+	if p.digits > 0 {
+		letters += p.digitChars
+	}
+	return dedupeChars(letters)
+}
+
+// calculateEntropy returns the min-entropy of the letters, which are built by a random walk over
+// the units, plus the entropy of the uppercase positions, the digit positions and the digits.
+// The walk is not uniform, as walks of longer units take fewer choices, so its Shannon entropy
+// would overstate how hard the passwords are to guess. The min-entropy is the entropy of the
+// most likely password instead, which an attacker guesses first. As no two walks produce the
+// same password, it is the probability of the most likely walk.
+func (p *PronounceableGenerator) calculateEntropy() float64 {
+	letters := p.length - p.digits
+
+	// entropy[kind][n] is the min-entropy of the walk over n letters starting with a unit of kind.
+	var entropy [2][]float64
+	entropy[0] = make([]float64, letters+1)
+	entropy[1] = make([]float64, letters+1)
+	for n := 1; n <= letters; n++ {
+		for kind := 0; kind < 2; kind++ {
+			units := fittingUnits(p.units[kind], n)
+			rest := math.Inf(1)
+			for _, unit := range units {
+				rest = min(rest, entropy[1-kind][n-len(unit)])
+			}
+			entropy[kind][n] = math.Log2(float64(len(units))) + rest
+		}
+	}
+
+	// One bit for the kind of the first unit.
+	total := 1 + min(entropy[0][letters], entropy[1][letters])
+	total += math.Log2(binomial(letters, p.uppercase))
+	total += math.Log2(binomial(p.length, p.digits)) + float64(p.digits)*math.Log2(float64(len(p.digitChars)))
+	return total
+}
+
+// fittingUnits returns the units which are not longer than n.
+func fittingUnits(units []string, n int) []string {
+	var fitting []string
+	for _, unit := range units {
+		if len(unit) <= n {
+			fitting = append(fitting, unit)
+		}
+	}
+	return fitting
+}
+
+// randomPositions returns n flags, k of which are set at uniformly random positions.
+func (g *Generator) randomPositions(n, k int) ([]bool, error) {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	// A partial Fisher-Yates shuffle draws the first k indexes.
+	set := make([]bool, n)
+	for i := 0; i < k; i++ {
+		j, err := g.randomIndex(n - i)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i], indexes[i+j] = indexes[i+j], indexes[i]
+		set[indexes[i]] = true
+	}
+	return set, nil
+}
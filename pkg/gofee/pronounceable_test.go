@@ -0,0 +1,187 @@
+package gofee
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// isPronounceable reports whether the letters of the password alternate between
+// runs of consonant units and vowel units.
+func isPronounceable(password string) bool {
+	letters := strings.ToLower(strings.Map(func(c rune) rune {
+		if unicode.IsDigit(c) {
+			return -1
+		}
+		return c
+	}, password))
+
+	for len(letters) > 0 {
+		isVowel := strings.ContainsRune(Vowels, rune(letters[0]))
+		end := strings.IndexFunc(letters, func(c rune) bool { return strings.ContainsRune(Vowels, c) != isVowel })
+		if end < 0 {
+			end = len(letters)
+		}
+
+		units := consonantUnits
+		if isVowel {
+			units = vowelUnits
+		}
+		if !containsString(units, letters[:end]) {
+			return false
+		}
+		letters = letters[end:]
+	}
+	return true
+}
+
+// containsString reports whether the list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// TestGeneratePronounceable checks the shape of pronounceable passwords.
+func TestGeneratePronounceable(t *testing.T) {
+	tests := []struct {
+		name      string
+		length    int
+		config    PasswordConfig
+		uppercase int
+		digits    int
+	}{
+		{
+			name:   "Lowercase",
+			length: 12,
+			config: PasswordConfig{Type: "pronounceable"},
+		},
+		{
+			name:      "Require all",
+			length:    10,
+			config:    PasswordConfig{Type: "pronounceable", IncludeUppers: true, IncludeDigits: true, RequireAll: true},
+			uppercase: 1,
+			digits:    1,
+		},
+		{
+			name:      "Minimum counts",
+			length:    16,
+			config:    PasswordConfig{Type: "pronounceable", IncludeUppers: true, IncludeDigits: true, MinUppers: 3, MinDigits: 4},
+			uppercase: 3,
+			digits:    4,
+		},
+		{
+			name:   "Single letter",
+			length: 1,
+			config: PasswordConfig{Type: "pronounceable"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				password, err := Generate(tt.length, tt.config)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+
+				if len(password) != tt.length {
+					t.Errorf("Generate() = %q, want length %d", password, tt.length)
+				}
+				if got := countIn(password, Uppers); got != tt.uppercase {
+					t.Errorf("Generate() = %q, want %d uppercase letters", password, tt.uppercase)
+				}
+				if got := countIn(password, Digits); got != tt.digits {
+					t.Errorf("Generate() = %q, want %d digits", password, tt.digits)
+				}
+				if !isPronounceable(password) {
+					t.Errorf("Generate() = %q, which is not made of consonant and vowel units", password)
+				}
+			}
+		})
+	}
+}
+
+// TestPronounceableExclusions checks that excluded characters are never used.
+func TestPronounceableExclusions(t *testing.T) {
+	config := PasswordConfig{Type: "pronounceable", IncludeUppers: true, IncludeDigits: true, RequireAll: true, ExcludeAmbiguous: true}
+
+	for i := 0; i < 100; i++ {
+		password, err := Generate(16, config)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if strings.ContainsAny(password, Ambiguous) {
+			t.Errorf("Generate() = %q, which contains ambiguous characters", password)
+		}
+	}
+}
+
+// TestPronounceableEntropy checks the min-entropy of short passwords against a manual calculation.
+func TestPronounceableEntropy(t *testing.T) {
+	pg, err := New(2, PasswordConfig{Type: "pronounceable"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Starting with a consonant: one of 30 fitting consonant units, a single one followed by one of
+	// 5 single vowels. Starting with a vowel: one of 11 fitting vowel units, a single one followed
+	// by one of 20 single consonants. The most likely passwords are the vowel pairs, such as "ai",
+	// with a probability of 1/2 * 1/11.
+	want := 1 + math.Log2(11)
+
+	if got := pg.Entropy(); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Entropy() = %v, want %v", got, want)
+	}
+
+	// The sprinkled characters add the entropy of their positions and values.
+	sprinkled, err := New(4, PasswordConfig{Type: "pronounceable", IncludeUppers: true, IncludeDigits: true, MinUppers: 1, MinDigits: 2})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	letters, err := New(2, PasswordConfig{Type: "pronounceable"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	want = letters.Entropy() + math.Log2(2) + math.Log2(6) + 2*math.Log2(10)
+	if got := sprinkled.Entropy(); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Entropy() = %v, want %v", got, want)
+	}
+
+	// The entropy is much lower than the one of random lowercase letters.
+	long, err := New(16, PasswordConfig{Type: "pronounceable"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := long.Entropy(); got >= 16*math.Log2(26) || got < 16 {
+		t.Errorf("Entropy() = %v, want between 16 and %v bits", got, 16*math.Log2(26))
+	}
+}
+
+// TestPronounceableErrors checks the rejected configs.
+func TestPronounceableErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+		config PasswordConfig
+	}{
+		{"Symbols", 10, PasswordConfig{Type: "pronounceable", IncludeSymbols: true, MinSymbols: 1}},
+		{"Excluded digits", 10, PasswordConfig{Type: "pronounceable", MinDigits: 1}},
+		{"Too short", 3, PasswordConfig{Type: "pronounceable", IncludeUppers: true, IncludeDigits: true, MinUppers: 2, MinDigits: 2}},
+		{"Only digits", 2, PasswordConfig{Type: "pronounceable", IncludeDigits: true, MinDigits: 2}},
+		{"Custom charset", 10, PasswordConfig{Type: "pronounceable", CustomCharset: "abc"}},
+		{"No vowels", 10, PasswordConfig{Type: "pronounceable", ExcludeChars: Vowels}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(tt.length, tt.config); err == nil {
+				t.Errorf("Generate() returned no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,557 @@
+package gofee
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// StrengthEstimate describes the estimated strength of a password.
+type StrengthEstimate struct {
+	Guesses      float64     // The estimated number of guesses needed to crack the password.
+	GuessesLog10 float64     // The order of magnitude of Guesses.
+	Score        int         // The strength from 0 (too guessable) to 4 (very unguessable).
+	CrackTimes   []CrackTime // The estimated time to crack the password in common attack scenarios.
+	Sequence     []Match     // The patterns the password was found to consist of.
+	Feedback     Feedback    // Advice for choosing a stronger password.
+}
+
+// CrackTime is the estimated time to crack a password in one attack scenario.
+type CrackTime struct {
+	Scenario string  `json:"scenario"` // The description of the attack scenario.
+	Seconds  float64 `json:"seconds"`  // The estimated time in seconds.
+	Display  string  `json:"display"`  // The time in a human readable form, such as "3 hours".
+}
+
+// Feedback explains the weaknesses of a password.
+type Feedback struct {
+	Warning     string   // The most important weakness, empty for strong passwords.
+	Suggestions []string // Suggestions for a stronger password.
+}
+
+// Scores of StrengthEstimate. Each score is reached at a number of guesses.
+const (
+	ScoreTooGuessable = iota
+	ScoreVeryGuessable
+	ScoreSomewhatGuessable
+	ScoreSafelyUnguessable
+	ScoreVeryUnguessable
+)
+
+// Scenarios and guessing rates (guesses per second) used for the crack time estimates.
+var crackScenarios = []struct {
+	name string
+	rate float64
+}{
+	{"online attack, throttled (100/hour)", 100.0 / 3600},
+	{"online attack, unthrottled (10/s)", 10},
+	{"offline attack, slow hash (10k/s)", 1e4},
+	{"offline attack, fast hash (10B/s)", 1e10},
+}
+
+const (
+	// maxStrengthRunes is the number of runes searched for patterns. Longer passwords are
+	// estimated on their beginning, with the rest counted as brute force.
+	maxStrengthRunes = 64
+	// bruteforceCardinality is the assumed number of possibilities per brute-forced character.
+	bruteforceCardinality = 10
+	// Minimum guesses of a match, which is only a part of the password.
+	minGuessesSingleChar = 10
+	minGuessesMultiChar  = 50
+	// minGuessesPerMatch penalizes passwords consisting of many simple matches.
+	minGuessesPerMatch = 10000
+	// minYearSpace is the minimum number of years assumed for years and dates.
+	minYearSpace = 20
+)
+
+// EstimateStrength estimates how hard the password is to guess. Like zxcvbn, it searches the
+// password for dictionary words (also reversed and with l33t substitutions), keyboard walks,
+// repeats, sequences, years and dates, and finds the combination of patterns that is the
+// easiest to guess. Words of userInputs, such as user names or e-mail addresses, are treated
+// as the most common words.
+func EstimateStrength(password string, userInputs ...string) StrengthEstimate {
+	m := &matcher{dictionaries: loadDictionaries()}
+	if len(userInputs) > 0 {
+		m.dictionaries = append([]*dictionary{newDictionary("user_inputs", userInputs)}, m.dictionaries...)
+	}
+
+	runes := []rune(password)
+	guesses, sequence := m.mostGuessable(runes)
+
+	estimate := StrengthEstimate{
+		Guesses:      guesses,
+		GuessesLog10: math.Log10(guesses),
+		Score:        guessesToScore(guesses),
+	}
+
+	for _, s := range crackScenarios {
+		seconds := guesses / s.rate
+		estimate.CrackTimes = append(estimate.CrackTimes, CrackTime{Scenario: s.name, Seconds: seconds, Display: displayTime(seconds)})
+	}
+
+	for _, match := range sequence {
+		estimate.Sequence = append(estimate.Sequence, *match)
+	}
+	estimate.Feedback = feedback(estimate.Score, sequence)
+
+	return estimate
+}
+
+// mostGuessable finds the sequence of non-overlapping matches covering the password with the least
+// number of guesses, like the search of zxcvbn. Gaps between matches are filled with brute force.
+func (m *matcher) mostGuessable(password []rune) (float64, []*Match) {
+	// Only search the beginning of long passwords, the rest is brute forced.
+	var rest []rune
+	if len(password) > maxStrengthRunes {
+		password, rest = password[:maxStrengthRunes], password[maxStrengthRunes:]
+	}
+
+	n := len(password)
+	if n == 0 {
+		return 1, nil
+	}
+
+	matches := m.match(password)
+	byEnd := make([][]*Match, n)
+	for _, match := range matches {
+		byEnd[match.J] = append(byEnd[match.J], match)
+	}
+
+	// optimal[k][l] holds the best sequence of l matches covering the password up to position k.
+	type candidate struct {
+		match *Match
+		pi    float64 // The product of the guesses of the matches.
+		g     float64 // The guesses of the sequence, including the penalties.
+	}
+	optimal := make([]map[int]candidate, n)
+	for k := range optimal {
+		optimal[k] = make(map[int]candidate)
+	}
+
+	update := func(match *Match, l int) {
+		k := match.J
+		pi := matchGuesses(match, n)
+		if l > 1 {
+			pi *= optimal[match.I-1][l-1].pi
+		}
+		g := factorial(l)*pi + math.Pow(minGuessesPerMatch, float64(l-1))
+
+		// Skip the match if a sequence of at most the same length is at least as good.
+		for competingL, competing := range optimal[k] {
+			if competingL <= l && competing.g <= g {
+				return
+			}
+		}
+		optimal[k][l] = candidate{match: match, pi: pi, g: g}
+	}
+
+	bruteforce := func(i, j int) *Match {
+		return &Match{Pattern: PatternBruteforce, I: i, J: j, Token: string(password[i : j+1])}
+	}
+
+	for k := 0; k < n; k++ {
+		for _, match := range byEnd[k] {
+			if match.I == 0 {
+				update(match, 1)
+				continue
+			}
+			for l := range optimal[match.I-1] {
+				update(match, l+1)
+			}
+		}
+
+		// Consider brute forcing the password from any position up to k.
+		update(bruteforce(0, k), 1)
+		for i := 1; i <= k; i++ {
+			for l, last := range optimal[i-1] {
+				// Adjacent brute force matches are always worse than a single one.
+				if last.match.Pattern != PatternBruteforce {
+					update(bruteforce(i, k), l+1)
+				}
+			}
+		}
+	}
+
+	// Pick the best sequence covering the whole password and unwind it.
+	bestL, best := 0, math.Inf(1)
+	for l, c := range optimal[n-1] {
+		if c.g < best || (c.g == best && l < bestL) {
+			bestL, best = l, c.g
+		}
+	}
+
+	sequence := make([]*Match, bestL)
+	for k, l := n-1, bestL; l > 0; l-- {
+		match := optimal[k][l].match
+		sequence[l-1] = match
+		k = match.I - 1
+	}
+
+	// Count the rest of long passwords as brute force.
+	if len(rest) > 0 {
+		best = math.Min(best*math.Pow(bruteforceCardinality, float64(len(rest))), math.MaxFloat64)
+	}
+
+	return best, sequence
+}
+
+// matchGuesses returns the estimated number of guesses for the match, which is part of a
+// password of n runes. The result is also stored in the match.
+func matchGuesses(match *Match, n int) float64 {
+	if match.Guesses != 0 {
+		return match.Guesses
+	}
+
+	length := match.J - match.I + 1
+
+	var guesses float64
+	switch match.Pattern {
+	case PatternBruteforce:
+		guesses = math.Pow(bruteforceCardinality, float64(length))
+		if length == 1 {
+			guesses = math.Max(guesses, minGuessesSingleChar+1)
+		} else {
+			guesses = math.Max(guesses, minGuessesMultiChar+1)
+		}
+	case PatternDictionary:
+		guesses = float64(match.Rank) * uppercaseVariations(match.Token) * l33tVariations(match)
+		if match.Reversed {
+			guesses *= 2
+		}
+	case PatternSpatial:
+		guesses = spatialGuesses(match)
+	case PatternRepeat:
+		guesses = match.BaseGuesses * float64(match.RepeatCount)
+	case PatternSequence:
+		guesses = sequenceGuesses(match)
+	case PatternYear:
+		guesses = math.Max(float64(abs(match.Year-referenceYear)), minYearSpace)
+	case PatternDate:
+		guesses = math.Max(float64(abs(match.Year-referenceYear)), minYearSpace) * 365
+		if match.Separator != "" {
+			guesses *= 4
+		}
+	}
+
+	// Matches that are only a part of the password need a minimum number of guesses.
+	if length < n {
+		if length == 1 {
+			guesses = math.Max(guesses, minGuessesSingleChar)
+		} else {
+			guesses = math.Max(guesses, minGuessesMultiChar)
+		}
+	}
+
+	match.Guesses = math.Max(guesses, 1)
+	return match.Guesses
+}
+
+// uppercaseVariations returns the number of ways a word can be capitalized like the token.
+// Capitalizing the first or last letter, or all letters, only doubles the guesses.
+func uppercaseVariations(token string) float64 {
+	runes := []rune(token)
+	if strings.ToLower(token) == token {
+		return 1
+	}
+	if isUpperRunes(runes[:1]) && strings.ToLower(string(runes[1:])) == string(runes[1:]) ||
+		isUpperRunes(runes[len(runes)-1:]) && strings.ToLower(string(runes[:len(runes)-1])) == string(runes[:len(runes)-1]) ||
+		strings.ToUpper(token) == token {
+		return 2
+	}
+
+	var upper, lower int
+	for _, c := range runes {
+		switch {
+		case strings.ToUpper(string(c)) == string(c) && strings.ToLower(string(c)) != string(c):
+			upper++
+		case strings.ToLower(string(c)) == string(c) && strings.ToUpper(string(c)) != string(c):
+			lower++
+		}
+	}
+
+	var variations float64
+	for i := 1; i <= min(upper, lower); i++ {
+		variations += binomial(upper+lower, i)
+	}
+	return variations
+}
+
+// l33tVariations returns the number of ways the l33t substitutions of the match can be applied.
+func l33tVariations(match *Match) float64 {
+	if !match.L33t {
+		return 1
+	}
+
+	variations := 1.0
+	for sub, letter := range match.Sub {
+		var subbed, unsubbed int
+		for _, c := range strings.ToLower(match.Token) {
+			switch c {
+			case sub:
+				subbed++
+			case letter:
+				unsubbed++
+			}
+		}
+
+		// Substituting all or none of the letters only doubles the guesses.
+		if subbed == 0 || unsubbed == 0 {
+			variations *= 2
+			continue
+		}
+
+		var possibilities float64
+		for i := 1; i <= min(subbed, unsubbed); i++ {
+			possibilities += binomial(subbed+unsubbed, i)
+		}
+		variations *= possibilities
+	}
+
+	return variations
+}
+
+// spatialGuesses returns the number of keyboard walks with the length, turns and shifted keys of the match.
+func spatialGuesses(match *Match) float64 {
+	var k *keyboard
+	for _, candidate := range keyboards {
+		if candidate.name == match.Keyboard {
+			k = candidate
+		}
+	}
+
+	length := match.J - match.I + 1
+	s := float64(k.startingPositions)
+	d := k.averageDegree
+
+	var guesses float64
+	for i := 2; i <= length; i++ {
+		for j := 1; j <= min(match.Turns, i-1); j++ {
+			guesses += binomial(i-1, j-1) * s * math.Pow(d, float64(j))
+		}
+	}
+
+	// Shifted keys add the ways to choose which keys are shifted.
+	if match.Shifted > 0 {
+		shifted, unshifted := match.Shifted, length-match.Shifted
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			var variations float64
+			for i := 1; i <= min(shifted, unshifted); i++ {
+				variations += binomial(shifted+unshifted, i)
+			}
+			guesses *= variations
+		}
+	}
+
+	return guesses
+}
+
+// sequenceGuesses returns the number of guesses for the sequence of the match.
+// Obvious starting characters like 'a' or '1' need less guesses, descending sequences more.
+func sequenceGuesses(match *Match) float64 {
+	first := []rune(match.Token)[0]
+
+	var base float64
+	switch {
+	case strings.ContainsRune("aAzZ019", first):
+		base = 4
+	case strings.ContainsRune(Digits, first):
+		base = 10
+	default:
+		base = 26
+	}
+
+	if !match.Ascending {
+		base *= 2
+	}
+
+	return base * float64(len([]rune(match.Token)))
+}
+
+// guessesToScore converts the number of guesses into a score from 0 to 4.
+func guessesToScore(guesses float64) int {
+	// The delta accounts for floating point imprecision around the thresholds.
+	const delta = 5
+
+	switch {
+	case guesses < 1e3+delta:
+		return ScoreTooGuessable
+	case guesses < 1e6+delta:
+		return ScoreVeryGuessable
+	case guesses < 1e8+delta:
+		return ScoreSomewhatGuessable
+	case guesses < 1e10+delta:
+		return ScoreSafelyUnguessable
+	default:
+		return ScoreVeryUnguessable
+	}
+}
+
+// displayTime formats a number of seconds, such as "less than a second", "5 hours" or "centuries".
+func displayTime(seconds float64) string {
+	const (
+		minute  = 60
+		hour    = minute * 60
+		day     = hour * 24
+		month   = day * 31
+		year    = month * 12
+		century = year * 100
+	)
+
+	units := []struct {
+		name    string
+		seconds float64
+		limit   float64
+	}{
+		{"second", 1, minute},
+		{"minute", minute, hour},
+		{"hour", hour, day},
+		{"day", day, month},
+		{"month", month, year},
+		{"year", year, century},
+	}
+
+	if seconds < 1 {
+		return "less than a second"
+	}
+	for _, u := range units {
+		if seconds < u.limit {
+			n := int(math.Round(seconds / u.seconds))
+			if n == 1 {
+				return fmt.Sprintf("1 %s", u.name)
+			}
+			return fmt.Sprintf("%d %ss", n, u.name)
+		}
+	}
+	return "centuries"
+}
+
+// feedback returns the warning and suggestions for a password with the score and sequence of matches.
+func feedback(score int, sequence []*Match) Feedback {
+	if len(sequence) == 0 {
+		return Feedback{Suggestions: []string{
+			"Use a few words, avoid common phrases.",
+			"No need for symbols, digits, or uppercase letters.",
+		}}
+	}
+
+	// Strong passwords need no advice.
+	if score > ScoreSomewhatGuessable {
+		return Feedback{}
+	}
+
+	// Give advice on the longest match, which dominates the password.
+	longest := sequence[0]
+	for _, match := range sequence[1:] {
+		if len([]rune(match.Token)) > len([]rune(longest.Token)) {
+			longest = match
+		}
+	}
+
+	fb := matchFeedback(longest, len(sequence) == 1)
+	fb.Suggestions = append([]string{"Add another word or two. Uncommon words are better."}, fb.Suggestions...)
+	return fb
+}
+
+// matchFeedback returns the warning and suggestions for a single match.
+// soleMatch reports whether the match is the whole password.
+func matchFeedback(match *Match, soleMatch bool) Feedback {
+	switch match.Pattern {
+	case PatternDictionary:
+		return dictionaryFeedback(match, soleMatch)
+	case PatternSpatial:
+		warning := "Short keyboard patterns are easy to guess."
+		if match.Turns == 1 {
+			warning = "Straight rows of keys are easy to guess."
+		}
+		return Feedback{Warning: warning, Suggestions: []string{"Use a longer keyboard pattern with more turns."}}
+	case PatternRepeat:
+		warning := `Repeats like "abcabcabc" are only slightly harder to guess than "abc".`
+		if len([]rune(match.BaseToken)) == 1 {
+			warning = `Repeats like "aaa" are easy to guess.`
+		}
+		return Feedback{Warning: warning, Suggestions: []string{"Avoid repeated words and characters."}}
+	case PatternSequence:
+		return Feedback{Warning: "Sequences like abc or 6543 are easy to guess.", Suggestions: []string{"Avoid sequences."}}
+	case PatternYear:
+		return Feedback{Warning: "Recent years are easy to guess.", Suggestions: []string{"Avoid recent years.", "Avoid years that are associated with you."}}
+	case PatternDate:
+		return Feedback{Warning: "Dates are often easy to guess.", Suggestions: []string{"Avoid dates and years that are associated with you."}}
+	}
+	return Feedback{}
+}
+
+// dictionaryFeedback returns the warning and suggestions for a dictionary match.
+func dictionaryFeedback(match *Match, soleMatch bool) Feedback {
+	var fb Feedback
+
+	switch match.Dictionary {
+	case "passwords":
+		switch {
+		case soleMatch && !match.L33t && !match.Reversed && match.Rank <= 10:
+			fb.Warning = "This is a top-10 common password."
+		case soleMatch && !match.L33t && !match.Reversed && match.Rank <= 100:
+			fb.Warning = "This is a top-100 common password."
+		case soleMatch && !match.L33t && !match.Reversed:
+			fb.Warning = "This is a very common password."
+		case math.Log10(match.Guesses) <= 4:
+			fb.Warning = "This is similar to a commonly used password."
+		}
+	case "english":
+		if soleMatch {
+			fb.Warning = "A word by itself is easy to guess."
+		}
+	case "female_names", "male_names", "surnames":
+		if soleMatch {
+			fb.Warning = "Names and surnames by themselves are easy to guess."
+		} else {
+			fb.Warning = "Common names and surnames are easy to guess."
+		}
+	case "user_inputs":
+		fb.Warning = "Personal information like your name is easy to guess."
+	}
+
+	token := []rune(match.Token)
+	switch {
+	case strings.ToUpper(match.Token) == match.Token && strings.ToLower(match.Token) != match.Token:
+		fb.Suggestions = append(fb.Suggestions, "All-uppercase is almost as easy to guess as all-lowercase.")
+	case isUpperRunes(token[:1]):
+		fb.Suggestions = append(fb.Suggestions, "Capitalization doesn't help very much.")
+	}
+
+	if match.Reversed && len(token) >= 4 {
+		fb.Suggestions = append(fb.Suggestions, "Reversed words aren't much harder to guess.")
+	}
+	if match.L33t {
+		fb.Suggestions = append(fb.Suggestions, "Predictable substitutions like '@' instead of 'a' don't help very much.")
+	}
+
+	return fb
+}
+
+// factorial returns n! as a float.
+func factorial(n int) float64 {
+	f := 1.0
+	for i := 2; i <= n; i++ {
+		f *= float64(i)
+	}
+	return f
+}
+
+// binomial returns the binomial coefficient "n choose k" as a float.
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	if k > n-k {
+		k = n - k
+	}
+
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r *= float64(n - k + i)
+		r /= float64(i)
+	}
+	return r
+}
@@ -0,0 +1,280 @@
+package gofee
+
+import (
+	"math"
+	"testing"
+)
+
+// TestEstimateStrength checks the score and dominating pattern of typical passwords.
+func TestEstimateStrength(t *testing.T) {
+	tests := []struct {
+		name        string
+		password    string
+		wantScore   int
+		wantPattern string
+		wantWarning string
+	}{
+		{
+			name:        "Top-10 password",
+			password:    "password",
+			wantScore:   ScoreTooGuessable,
+			wantPattern: PatternDictionary,
+			wantWarning: "This is a top-10 common password.",
+		},
+		{
+			name:        "L33t password",
+			password:    "P@ssw0rd",
+			wantScore:   ScoreTooGuessable,
+			wantPattern: PatternDictionary,
+			wantWarning: "This is similar to a commonly used password.",
+		},
+		{
+			name:        "Reversed password",
+			password:    "drowssap",
+			wantScore:   ScoreTooGuessable,
+			wantPattern: PatternDictionary,
+			wantWarning: "This is similar to a commonly used password.",
+		},
+		{
+			name:        "Keyboard walk",
+			password:    "zxcvfdsa",
+			wantScore:   ScoreVeryGuessable,
+			wantPattern: PatternSpatial,
+			wantWarning: "Short keyboard patterns are easy to guess.",
+		},
+		{
+			name:        "Repeat",
+			password:    "aaaaaaaaaa",
+			wantScore:   ScoreTooGuessable,
+			wantPattern: PatternRepeat,
+			wantWarning: `Repeats like "aaa" are easy to guess.`,
+		},
+		{
+			name:        "Sequence",
+			password:    "9876543",
+			wantScore:   ScoreTooGuessable,
+			wantPattern: PatternSequence,
+			wantWarning: "Sequences like abc or 6543 are easy to guess.",
+		},
+		{
+			name:        "Date",
+			password:    "13.04.1987",
+			wantScore:   ScoreVeryGuessable,
+			wantPattern: PatternDate,
+			wantWarning: "Dates are often easy to guess.",
+		},
+		{
+			name:        "Random characters",
+			password:    "xK#9vT!q2@Lm",
+			wantScore:   ScoreVeryUnguessable,
+			wantPattern: PatternBruteforce,
+		},
+		{
+			name:        "Passphrase",
+			password:    "correcthorsebatterystaple",
+			wantScore:   ScoreVeryUnguessable,
+			wantPattern: PatternDictionary,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateStrength(tt.password)
+
+			if got.Score != tt.wantScore {
+				t.Errorf("EstimateStrength(%q) score = %d, want %d", tt.password, got.Score, tt.wantScore)
+			}
+			if len(got.Sequence) == 0 || got.Sequence[0].Pattern != tt.wantPattern {
+				t.Errorf("EstimateStrength(%q) sequence = %+v, want %s first", tt.password, got.Sequence, tt.wantPattern)
+			}
+			if got.Feedback.Warning != tt.wantWarning {
+				t.Errorf("EstimateStrength(%q) warning = %q, want %q", tt.password, got.Feedback.Warning, tt.wantWarning)
+			}
+			if math.Abs(got.GuessesLog10-math.Log10(got.Guesses)) > 1e-9 {
+				t.Errorf("EstimateStrength(%q) guesses log10 = %v, want %v", tt.password, got.GuessesLog10, math.Log10(got.Guesses))
+			}
+			if len(got.CrackTimes) != len(crackScenarios) {
+				t.Errorf("EstimateStrength(%q) has %d crack times, want %d", tt.password, len(got.CrackTimes), len(crackScenarios))
+			}
+
+			// The sequence covers the whole password without gaps.
+			next := 0
+			for _, m := range got.Sequence {
+				if m.I != next {
+					t.Errorf("EstimateStrength(%q) sequence has a gap before %+v", tt.password, m)
+				}
+				next = m.J + 1
+			}
+			if next != len([]rune(tt.password)) {
+				t.Errorf("EstimateStrength(%q) sequence ends at %d, want %d", tt.password, next, len([]rune(tt.password)))
+			}
+		})
+	}
+}
+
+// TestEstimateStrengthUserInputs checks that user inputs are treated as the most common words.
+func TestEstimateStrengthUserInputs(t *testing.T) {
+	password := "Wehrlegofee"
+
+	without := EstimateStrength(password)
+	with := EstimateStrength(password, "wehrle", "gofee")
+
+	if with.Guesses >= without.Guesses {
+		t.Errorf("EstimateStrength() with user inputs = %v guesses, want less than %v", with.Guesses, without.Guesses)
+	}
+	if with.Sequence[0].Dictionary != "user_inputs" {
+		t.Errorf("EstimateStrength() first match = %+v, want a user input", with.Sequence[0])
+	}
+}
+
+// TestEstimateStrengthEmpty checks the estimate of an empty password.
+func TestEstimateStrengthEmpty(t *testing.T) {
+	got := EstimateStrength("")
+
+	if got.Guesses != 1 || got.Score != ScoreTooGuessable || len(got.Sequence) != 0 {
+		t.Errorf("EstimateStrength(\"\") = %+v, want 1 guess and no matches", got)
+	}
+	if len(got.Feedback.Suggestions) == 0 {
+		t.Errorf("EstimateStrength(\"\") has no suggestions")
+	}
+}
+
+// TestEstimateStrengthLong checks that long passwords are estimated without overflowing.
+func TestEstimateStrengthLong(t *testing.T) {
+	password := ""
+	for i := 0; i < 20; i++ {
+		password += "x9#Kq"
+	}
+
+	got := EstimateStrength(password)
+	if math.IsInf(got.Guesses, 0) || math.IsNaN(got.Guesses) || got.Score != ScoreVeryUnguessable {
+		t.Errorf("EstimateStrength() of %d runes = %v guesses, score %d", len(password), got.Guesses, got.Score)
+	}
+}
+
+// TestMatchGuesses checks the number of guesses of single matches.
+func TestMatchGuesses(t *testing.T) {
+	tests := []struct {
+		name  string
+		match Match
+		want  float64
+	}{
+		{
+			name:  "Lowercase word",
+			match: Match{Pattern: PatternDictionary, Token: "horse", J: 4, Rank: 494},
+			want:  494,
+		},
+		{
+			name:  "Capitalized reversed word",
+			match: Match{Pattern: PatternDictionary, Token: "Esroh", J: 4, Rank: 494, Reversed: true},
+			want:  494 * 2 * 2,
+		},
+		{
+			name:  "L33t word",
+			match: Match{Pattern: PatternDictionary, Token: "h0rse", J: 4, Rank: 494, L33t: true, Sub: map[rune]rune{'0': 'o'}},
+			want:  494 * 2,
+		},
+		{
+			name:  "Ascending sequence from a",
+			match: Match{Pattern: PatternSequence, Token: "abcd", J: 3, Ascending: true},
+			want:  4 * 4,
+		},
+		{
+			name:  "Descending digit sequence",
+			match: Match{Pattern: PatternSequence, Token: "8765", J: 3},
+			want:  10 * 2 * 4,
+		},
+		{
+			name:  "Repeat",
+			match: Match{Pattern: PatternRepeat, Token: "abab", J: 3, BaseGuesses: 12, RepeatCount: 2},
+			want:  24,
+		},
+		{
+			name:  "Year",
+			match: Match{Pattern: PatternYear, Token: "1950", J: 3, Year: referenceYear - 70},
+			want:  70,
+		},
+		{
+			name:  "Date with separator",
+			match: Match{Pattern: PatternDate, Token: "1.1.2020", J: 7, Year: referenceYear, Separator: "."},
+			want:  minYearSpace * 365 * 4,
+		},
+		{
+			name:  "Bruteforce",
+			match: Match{Pattern: PatternBruteforce, Token: "x9#", J: 2},
+			want:  1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := tt.match
+			if got := matchGuesses(&match, match.J+1); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("matchGuesses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUppercaseVariations tests the number of capitalizations of words.
+func TestUppercaseVariations(t *testing.T) {
+	tests := []struct {
+		token string
+		want  float64
+	}{
+		{"horse", 1},
+		{"Horse", 2},
+		{"horsE", 2},
+		{"HORSE", 2},
+		{"hOrSe", binomial(5, 1) + binomial(5, 2)},
+	}
+
+	for _, tt := range tests {
+		if got := uppercaseVariations(tt.token); got != tt.want {
+			t.Errorf("uppercaseVariations(%q) = %v, want %v", tt.token, got, tt.want)
+		}
+	}
+}
+
+// TestDisplayTime tests the formatting of crack times.
+func TestDisplayTime(t *testing.T) {
+	tests := []struct {
+		seconds float64
+		want    string
+	}{
+		{0.5, "less than a second"},
+		{1, "1 second"},
+		{59, "59 seconds"},
+		{3 * 3600, "3 hours"},
+		{2 * 86400, "2 days"},
+		{5 * 365 * 86400, "5 years"},
+		{1e12, "centuries"},
+	}
+
+	for _, tt := range tests {
+		if got := displayTime(tt.seconds); got != tt.want {
+			t.Errorf("displayTime(%v) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+// TestGuessesToScore tests the thresholds of the scores.
+func TestGuessesToScore(t *testing.T) {
+	tests := []struct {
+		guesses float64
+		want    int
+	}{
+		{1, ScoreTooGuessable},
+		{1e3, ScoreTooGuessable},
+		{1e4, ScoreVeryGuessable},
+		{1e7, ScoreSomewhatGuessable},
+		{1e9, ScoreSafelyUnguessable},
+		{1e11, ScoreVeryUnguessable},
+	}
+
+	for _, tt := range tests {
+		if got := guessesToScore(tt.guesses); got != tt.want {
+			t.Errorf("guessesToScore(%v) = %d, want %d", tt.guesses, got, tt.want)
+		}
+	}
+}
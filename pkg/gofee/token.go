@@ -0,0 +1,188 @@
+package gofee
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"strings"
+	"time"
+)
+
+// Formats of tokens.
+const (
+	TokenHex       = "hex"       // Lowercase hexadecimal.
+	TokenBase32    = "base32"    // Base32 of RFC 4648 without padding.
+	TokenBase64    = "base64"    // Base64 of RFC 4648 with padding.
+	TokenBase64URL = "base64url" // URL-safe Base64 of RFC 4648 without padding.
+	TokenURLSafe   = "urlsafe"   // Letters and digits, which are safe in URLs, file names and shells.
+	TokenAPIKey    = "apikey"    // Prefixed letters and digits with a checksum, such as gof_...
+	TokenUUIDv4    = "uuid4"     // Random UUID of RFC 9562.
+	TokenUUIDv7    = "uuid7"     // Time-ordered UUID of RFC 9562.
+)
+
+// TokenFormats lists the token formats in the order they are documented.
+var TokenFormats = []string{TokenHex, TokenBase32, TokenBase64, TokenBase64URL, TokenURLSafe, TokenAPIKey, TokenUUIDv4, TokenUUIDv7}
+
+// Defaults of tokens.
+const (
+	TokenSize      = 32    // The number of random bytes of a token, 256 bits.
+	APIKeyPrefix   = "gof" // The prefix of API keys.
+	apiKeyChecksum = 6     // The number of base62 characters of the CRC32 checksum of API keys.
+)
+
+// base62 are the characters of URL-safe tokens and API keys.
+const base62 = Digits + Uppers + Lowers
+
+// tokenNow returns the time of UUIDv7 tokens. It is replaced in tests.
+var tokenNow = time.Now
+
+// TokenConfig describes a token.
+type TokenConfig struct {
+	Format string // One of TokenFormats.
+	Size   int    // The number of random bytes, TokenSize if 0. UUIDs have a fixed size.
+	Prefix string // The prefix of API keys, APIKeyPrefix if empty.
+}
+
+// GenerateToken creates a random token using the default Generator.
+func GenerateToken(config TokenConfig) (Result, error) {
+	return defaultGenerator.GenerateToken(config)
+}
+
+// GenerateToken creates a random token, such as an API key or a session identifier.
+// The entropy of the result is the number of random bits of the token.
+func (g *Generator) GenerateToken(config TokenConfig) (Result, error) {
+	size := config.Size
+	if size == 0 {
+		size = TokenSize
+	}
+	if size < 0 {
+		return Result{}, fmt.Errorf("size must be greater than 0")
+	}
+
+	switch config.Format {
+	case TokenHex, TokenBase32, TokenBase64, TokenBase64URL:
+		b, err := g.randomBytes(size)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Password: encodeToken(config.Format, b), Entropy: float64(8 * size)}, nil
+
+	case TokenURLSafe:
+		length := base62Length(size)
+		token, err := g.mapToCharset(length, base62)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Password: token, Charset: base62, Entropy: base62Entropy(length)}, nil
+
+	case TokenAPIKey:
+		prefix := config.Prefix
+		if prefix == "" {
+			prefix = APIKeyPrefix
+		}
+		if strings.Trim(prefix, Lowers+Digits) != "" {
+			return Result{}, fmt.Errorf("prefix must consist of lowercase letters and digits")
+		}
+		length := base62Length(size)
+		random, err := g.mapToCharset(length, base62)
+		if err != nil {
+			return Result{}, err
+		}
+		key := prefix + "_" + random
+		return Result{Password: key + apiKeyChecksumOf(key), Charset: base62, Entropy: base62Entropy(length)}, nil
+
+	case TokenUUIDv4, TokenUUIDv7:
+		uuid, entropy, err := g.uuid(config.Format)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Password: uuid, Entropy: entropy}, nil
+	}
+
+	return Result{}, fmt.Errorf("unknown token format %q (supported: %s)", config.Format, strings.Join(TokenFormats, ", "))
+}
+
+// encodeToken encodes random bytes in one of the binary-to-text formats.
+func encodeToken(format string, b []byte) string {
+	switch format {
+	case TokenBase32:
+		return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+	case TokenBase64:
+		return base64.StdEncoding.EncodeToString(b)
+	case TokenBase64URL:
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	return hex.EncodeToString(b)
+}
+
+// base62Length returns the number of base62 characters holding at least size random bytes.
+func base62Length(size int) int {
+	return int(math.Ceil(float64(8*size) / math.Log2(float64(len(base62)))))
+}
+
+// base62Entropy returns the entropy of length random base62 characters.
+func base62Entropy(length int) float64 {
+	return float64(length) * math.Log2(float64(len(base62)))
+}
+
+// apiKeyChecksumOf returns the CRC32 (IEEE) checksum of the prefix and the random part of an
+// API key, as a base62 number of 6 digits. Like the checksums of GitHub tokens, it lets scanners
+// and services reject mistyped or made-up keys without a lookup.
+func apiKeyChecksumOf(key string) string {
+	sum := crc32.ChecksumIEEE([]byte(key))
+	var b [apiKeyChecksum]byte
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = base62[sum%62]
+		sum /= 62
+	}
+	return string(b[:])
+}
+
+// CheckAPIKey reports whether the checksum of an API key is valid.
+func CheckAPIKey(key string) bool {
+	prefix, rest, ok := strings.Cut(key, "_")
+	if !ok || prefix == "" || len(rest) <= apiKeyChecksum {
+		return false
+	}
+	body, checksum := key[:len(key)-apiKeyChecksum], key[len(key)-apiKeyChecksum:]
+	return apiKeyChecksumOf(body) == checksum
+}
+
+// uuid returns a UUID of version 4 or 7 and its number of random bits.
+func (g *Generator) uuid(format string) (string, float64, error) {
+	b, err := g.randomBytes(16)
+	if err != nil {
+		return "", 0, err
+	}
+
+	entropy := 122.0
+	version := byte(4)
+	if format == TokenUUIDv7 {
+		// The first 48 bits are the Unix time in milliseconds, so UUIDs sort by creation.
+		ms := uint64(tokenNow().UnixMilli())
+		binary.BigEndian.PutUint16(b[0:], uint16(ms>>32))
+		binary.BigEndian.PutUint32(b[2:], uint32(ms))
+		entropy = 74
+		version = 7
+	}
+	b[6] = b[6]&0x0f | version<<4 // The version in the high 4 bits.
+	b[8] = b[8]&0x3f | 0x80       // The variant of RFC 9562 in the high 2 bits.
+
+	s := hex.EncodeToString(b)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32], entropy, nil
+}
+
+// randomBytes returns n random bytes.
+func (g *Generator) randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.readRandom(b); err != nil {
+		return nil, fmt.Errorf("error generating random bytes: %v", err)
+	}
+	return b, nil
+}
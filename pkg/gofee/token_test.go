@@ -0,0 +1,173 @@
+package gofee
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGenerateTokenEncodings checks the encodings of known random bytes.
+func TestGenerateTokenEncodings(t *testing.T) {
+	random := []byte{0xfb, 0xff, 0xfe, 0x00, 0x10}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{TokenHex, "fbfffe0010"},
+		{TokenBase32, "7P774AAQ"},
+		{TokenBase64, "+//+ABA="},
+		{TokenBase64URL, "-__-ABA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			g := NewGenerator(bytes.NewReader(random))
+			result, err := g.GenerateToken(TokenConfig{Format: tt.format, Size: len(random)})
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+			if result.Password != tt.want {
+				t.Errorf("GenerateToken() = %q, want %q", result.Password, tt.want)
+			}
+			if result.Entropy != 40 {
+				t.Errorf("Entropy = %.2f, want 40", result.Entropy)
+			}
+		})
+	}
+}
+
+// TestGenerateTokenSizes checks the lengths and the entropy of random tokens.
+func TestGenerateTokenSizes(t *testing.T) {
+	tests := []struct {
+		format  string
+		size    int
+		pattern string
+		entropy float64
+	}{
+		// The default size is 32 bytes.
+		{TokenHex, 0, `^[0-9a-f]{64}$`, 256},
+		{TokenBase32, 0, `^[A-Z2-7]{52}$`, 256},
+		{TokenBase64, 0, `^[A-Za-z0-9+/]{43}=$`, 256},
+		{TokenBase64URL, 16, `^[A-Za-z0-9_-]{22}$`, 128},
+		// 22 base62 characters hold 130.99 bits, the fewest for 16 bytes.
+		{TokenURLSafe, 16, `^[A-Za-z0-9]{22}$`, 130.99},
+		{TokenURLSafe, 0, `^[A-Za-z0-9]{43}$`, 256.03},
+		{TokenAPIKey, 0, `^gof_[A-Za-z0-9]{49}$`, 256.03},
+		{TokenUUIDv4, 0, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, 122},
+		// The size is ignored by UUIDs.
+		{TokenUUIDv7, 64, `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, 74},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			result, err := GenerateToken(TokenConfig{Format: tt.format, Size: tt.size})
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+			if !regexp.MustCompile(tt.pattern).MatchString(result.Password) {
+				t.Errorf("GenerateToken() = %q, want %s", result.Password, tt.pattern)
+			}
+			if result.Entropy < tt.entropy || result.Entropy > tt.entropy+0.01 {
+				t.Errorf("Entropy = %.2f, want %.2f", result.Entropy, tt.entropy)
+			}
+		})
+	}
+}
+
+// TestGenerateTokenUUID checks UUIDs against the example of RFC 9562, appendix A.
+func TestGenerateTokenUUID(t *testing.T) {
+	defer func(now func() time.Time) { tokenNow = now }(tokenNow)
+	tokenNow = func() time.Time { return time.UnixMilli(0x017f22e279b0) }
+
+	// The first 6 bytes are replaced by the time, and the version and variant bits are set.
+	random := []byte{0, 0, 0, 0, 0, 0, 0x0c, 0xc3, 0x18, 0xc4, 0xdc, 0x0c, 0x0c, 0x07, 0x39, 0x8f}
+	result, err := NewGenerator(bytes.NewReader(random)).GenerateToken(TokenConfig{Format: TokenUUIDv7})
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if want := "017f22e2-79b0-7cc3-98c4-dc0c0c07398f"; result.Password != want {
+		t.Errorf("GenerateToken() = %q, want %q", result.Password, want)
+	}
+
+	random = []byte{0x91, 0x9, 0x8, 0x27, 0x32, 0x13, 0x4, 0x89, 0x3b, 0x1, 0xcc, 0x27, 0x3b, 0x65, 0x5a, 0x32}
+	result, err = NewGenerator(bytes.NewReader(random)).GenerateToken(TokenConfig{Format: TokenUUIDv4})
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if want := "91090827-3213-4489-bb01-cc273b655a32"; result.Password != want {
+		t.Errorf("GenerateToken() = %q, want %q", result.Password, want)
+	}
+}
+
+// TestAPIKey checks the prefix and the checksum of API keys.
+func TestAPIKey(t *testing.T) {
+	result, err := GenerateToken(TokenConfig{Format: TokenAPIKey, Size: 24, Prefix: "acme2"})
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	key := result.Password
+	if !strings.HasPrefix(key, "acme2_") || len(key) != len("acme2_")+33+6 {
+		t.Fatalf("GenerateToken() = %q, want acme2_ and 39 characters", key)
+	}
+	if !CheckAPIKey(key) {
+		t.Errorf("CheckAPIKey(%q) = false, want true", key)
+	}
+
+	// Changing any character breaks the checksum.
+	for i := range key {
+		if key[i] == '_' {
+			continue
+		}
+		c := byte('a')
+		if key[i] == c {
+			c = 'b'
+		}
+		mistyped := key[:i] + string(c) + key[i+1:]
+		if CheckAPIKey(mistyped) {
+			t.Errorf("CheckAPIKey(%q) = true, want false", mistyped)
+		}
+	}
+
+	for _, key := range []string{"", "gof", "gof_", "gof_000000", "_abcdefabcdef"} {
+		if CheckAPIKey(key) {
+			t.Errorf("CheckAPIKey(%q) = true, want false", key)
+		}
+	}
+}
+
+// TestAPIKeyChecksum checks the checksum of a known key, which must never change.
+func TestAPIKeyChecksum(t *testing.T) {
+	// CRC32("gof_abc") is 0x65a61553, which is 1rPc5T in base62.
+	if got := apiKeyChecksumOf("gof_abc"); got != "1rPc5T" {
+		t.Errorf("apiKeyChecksumOf() = %q, want 1rPc5T", got)
+	}
+	if !CheckAPIKey("gof_abc1rPc5T") {
+		t.Errorf("CheckAPIKey() = false, want true")
+	}
+}
+
+// TestGenerateTokenErrors checks that invalid configs are rejected.
+func TestGenerateTokenErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TokenConfig
+		wantErr string
+	}{
+		{"Unknown format", TokenConfig{Format: "base58"}, "unknown token format"},
+		{"Negative size", TokenConfig{Format: TokenHex, Size: -1}, "size"},
+		{"Uppercase prefix", TokenConfig{Format: TokenAPIKey, Prefix: "GOF"}, "prefix"},
+		{"Underscore in prefix", TokenConfig{Format: TokenAPIKey, Prefix: "my_app"}, "prefix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateToken(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GenerateToken() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package gofee
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// The statistical tests in this file generate large samples of passwords of every type and check
+// that their characters are uniformly distributed, independent of their position and of each
+// other. They catch regressions such as modulo bias or a shuffle that favours some positions.
+//
+// Every test rejects at a significance level of at most one in a million, so a correct generator
+// fails practically never, while the biases they look for are far above the threshold.
+
+// uniformityZ is the two-sided critical value of the standard normal distribution at a
+// significance level of 1e-6.
+const uniformityZ = 4.892
+
+// chiSquaredCritical returns the critical value of the chi-squared distribution with df degrees
+// of freedom at the significance level of uniformityZ, using the Wilson-Hilferty approximation.
+func chiSquaredCritical(df int) float64 {
+	k := float64(df)
+	return k * math.Pow(1-2/(9*k)+uniformityZ*math.Sqrt(2/(9*k)), 3)
+}
+
+// chiSquared returns the chi-squared statistic of the counts against a uniform distribution.
+func chiSquared(counts []int, total int) float64 {
+	expected := float64(total) / float64(len(counts))
+
+	var stat float64
+	for _, count := range counts {
+		d := float64(count) - expected
+		stat += d * d / expected
+	}
+	return stat
+}
+
+// checkUniform fails the test if the counts are not uniformly distributed.
+func checkUniform(t *testing.T, what string, counts []int) {
+	t.Helper()
+
+	var total int
+	for _, count := range counts {
+		total += count
+	}
+	if stat, critical := chiSquared(counts, total), chiSquaredCritical(len(counts)-1); stat > critical {
+		t.Errorf("%s: chi-squared = %.2f, want at most %.2f for a uniform distribution", what, stat, critical)
+	}
+}
+
+// checkHomogeneous fails the test if the rows of the contingency table do not share the same
+// distribution, using the chi-squared test of homogeneity.
+func checkHomogeneous(t *testing.T, what string, table [][]int) {
+	t.Helper()
+
+	rows := make([]float64, len(table))
+	cols := make([]float64, len(table[0]))
+	var total float64
+	for i, row := range table {
+		for j, count := range row {
+			rows[i] += float64(count)
+			cols[j] += float64(count)
+			total += float64(count)
+		}
+	}
+
+	var stat float64
+	for i, row := range table {
+		for j, count := range row {
+			expected := rows[i] * cols[j] / total
+			d := float64(count) - expected
+			stat += d * d / expected
+		}
+	}
+
+	df := (len(rows) - 1) * (len(cols) - 1)
+	if critical := chiSquaredCritical(df); stat > critical {
+		t.Errorf("%s: chi-squared = %.2f, want at most %.2f for homogeneous rows", what, stat, critical)
+	}
+}
+
+// checkSerialCorrelation fails the test if consecutive values of the sequence are correlated.
+// For independent values, the lag-1 autocorrelation r is approximately normal with a standard
+// deviation of 1/sqrt(n).
+func checkSerialCorrelation(t *testing.T, what string, seq []int) {
+	t.Helper()
+
+	var mean float64
+	for _, x := range seq {
+		mean += float64(x)
+	}
+	mean /= float64(len(seq))
+
+	var cov, variance float64
+	for i, x := range seq {
+		d := float64(x) - mean
+		variance += d * d
+		if i > 0 {
+			cov += d * (float64(seq[i-1]) - mean)
+		}
+	}
+
+	r := cov / variance
+	if z := r * math.Sqrt(float64(len(seq))); math.Abs(z) > uniformityZ {
+		t.Errorf("%s: serial correlation = %.4f (z = %.2f), want uncorrelated values", what, r, z)
+	}
+}
+
+// checkRuns fails the test if the sequence has too many or too few runs of values below and
+// above its mean, using the Wald-Wolfowitz runs test.
+func checkRuns(t *testing.T, what string, seq []int) {
+	t.Helper()
+
+	var mean float64
+	for _, x := range seq {
+		mean += float64(x)
+	}
+	mean /= float64(len(seq))
+
+	var below, above, runs float64
+	for i, x := range seq {
+		isAbove := float64(x) >= mean
+		if isAbove {
+			above++
+		} else {
+			below++
+		}
+		if i == 0 || isAbove != (float64(seq[i-1]) >= mean) {
+			runs++
+		}
+	}
+
+	n := below + above
+	expected := 2*below*above/n + 1
+	variance := 2 * below * above * (2*below*above - n) / (n * n * (n - 1))
+	if z := (runs - expected) / math.Sqrt(variance); math.Abs(z) > uniformityZ {
+		t.Errorf("%s: %v runs, want about %.0f (z = %.2f)", what, runs, expected, z)
+	}
+}
+
+// checkSequence runs the tests of independence on the sequence.
+func checkSequence(t *testing.T, what string, seq []int) {
+	t.Helper()
+	checkSerialCorrelation(t, what, seq)
+	checkRuns(t, what, seq)
+}
+
+// samplePasswords generates n passwords of the config and returns them with their charset.
+func samplePasswords(t *testing.T, n, length int, config PasswordConfig) ([][]rune, string) {
+	t.Helper()
+
+	pg, err := New(length, config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	passwords := make([][]rune, n)
+	for i := range passwords {
+		password, err := pg.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		passwords[i] = []rune(password)
+	}
+
+	var charset string
+	if c, ok := pg.(interface{ Charset() string }); ok {
+		charset = c.Charset()
+	}
+	return passwords, charset
+}
+
+// runeIndexes maps every rune of chars to its position in chars.
+func runeIndexes(chars string) map[rune]int {
+	indexes := make(map[rune]int, utf8.RuneCountInString(chars))
+	for _, c := range chars {
+		indexes[c] = len(indexes)
+	}
+	return indexes
+}
+
+// TestUniformityCharset checks passwords drawn from a charset: every character is equally likely
+// overall and at every position, and consecutive characters are independent.
+func TestUniformityCharset(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+		config PasswordConfig
+	}{
+		{"All classes", 16, PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true}},
+		{"PIN", 8, PasswordConfig{Type: "pin"}},
+		{"Three characters", 12, PasswordConfig{CustomCharset: "abc"}},
+		{"No ambiguous characters", 16, PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, ExcludeAmbiguous: true}},
+		{"Unicode", 12, PasswordConfig{CustomCharset: "äöüßжяфы€😀🎉"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passwords, charset := samplePasswords(t, 20000, tt.length, tt.config)
+			indexes := runeIndexes(charset)
+
+			counts := make([]int, len(indexes))
+			positions := make([][]int, tt.length)
+			var seq []int
+			for _, password := range passwords {
+				for i, c := range password {
+					if positions[i] == nil {
+						positions[i] = make([]int, len(indexes))
+					}
+					counts[indexes[c]]++
+					positions[i][indexes[c]]++
+					seq = append(seq, indexes[c])
+				}
+			}
+
+			checkUniform(t, "characters", counts)
+			for i, position := range positions {
+				checkUniform(t, "position "+strconv.Itoa(i), position)
+			}
+			checkSequence(t, "characters", seq)
+		})
+	}
+}
+
+// TestUniformityConstrained checks passwords with minimum counts: the characters of every class
+// are equally likely, and the classes are distributed alike over all positions, so the shuffle
+// leaves no positional skew.
+func TestUniformityConstrained(t *testing.T) {
+	config := PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, IncludeSymbols: true, MinDigits: 3, MinSymbols: 2}
+	passwords, _ := samplePasswords(t, 20000, 12, config)
+
+	classes := []string{Lowers, Uppers, Digits, Symbols}
+	classOf := func(c rune) int {
+		for i, class := range classes {
+			if strings.ContainsRune(class, c) {
+				return i
+			}
+		}
+		t.Fatalf("character %q in no class", c)
+		return -1
+	}
+
+	counts := make([][]int, len(classes))
+	for i, class := range classes {
+		counts[i] = make([]int, len(class))
+	}
+	table := make([][]int, 12)
+	for i := range table {
+		table[i] = make([]int, len(classes))
+	}
+
+	// The classes of consecutive characters are not independent, as the required characters
+	// are drawn without replacement, so the sequence tests do not apply.
+	for _, password := range passwords {
+		for i, c := range password {
+			class := classOf(c)
+			counts[class][strings.IndexRune(classes[class], c)]++
+			table[i][class]++
+		}
+	}
+
+	for i, class := range classes {
+		checkUniform(t, "class "+class, counts[i])
+	}
+	checkHomogeneous(t, "classes per position", table)
+}
+
+// TestUniformityPattern checks that every position of a pattern is uniform over its class.
+func TestUniformityPattern(t *testing.T) {
+	classes := []string{strings.ToUpper(Consonants), Vowels, Digits, "", Symbols, "0123456789abcdef"}
+	passwords, _ := samplePasswords(t, 20000, 1, PasswordConfig{Pattern: "Cvd-sh"})
+
+	for i, class := range classes {
+		if class == "" {
+			continue
+		}
+
+		counts := make([]int, len(class))
+		seq := make([]int, len(passwords))
+		for j, password := range passwords {
+			index := strings.IndexRune(class, password[i])
+			if index < 0 {
+				t.Fatalf("position %d of %q is not in %q", i, string(password), class)
+			}
+			counts[index]++
+			seq[j] = index
+		}
+
+		checkUniform(t, "position "+strconv.Itoa(i), counts)
+		checkSequence(t, "position "+strconv.Itoa(i), seq)
+	}
+}
+
+// TestUniformityFirstChars checks that restricting the first character keeps it uniform over the
+// allowed characters and leaves the other positions uniform over the charset.
+func TestUniformityFirstChars(t *testing.T) {
+	config := PasswordConfig{IncludeLowers: true, IncludeUppers: true, IncludeDigits: true, FirstChars: Uppers}
+	passwords, charset := samplePasswords(t, 20000, 8, config)
+	indexes := runeIndexes(charset)
+
+	first := make([]int, len(Uppers))
+	rest := make([]int, len(indexes))
+	for _, password := range passwords {
+		first[strings.IndexRune(Uppers, password[0])]++
+		for _, c := range password[1:] {
+			rest[indexes[c]]++
+		}
+	}
+
+	checkUniform(t, "first character", first)
+	checkUniform(t, "other characters", rest)
+}
+
+// TestUniformityPronounceable checks the random parts of pronounceable passwords: the digits,
+// the positions of the digits and which of the letters are uppercased.
+func TestUniformityPronounceable(t *testing.T) {
+	const length = 10
+	config := PasswordConfig{Type: "pronounceable", IncludeUppers: true, IncludeDigits: true, MinUppers: 2, MinDigits: 2}
+	passwords, _ := samplePasswords(t, 20000, length, config)
+
+	digits := make([]int, len(Digits))
+	digitPositions := make([]int, length)
+	upperPositions := make([]int, length-2)
+	var seq []int
+	for _, password := range passwords {
+		letter := 0
+		for i, c := range password {
+			switch {
+			case strings.ContainsRune(Digits, c):
+				digits[c-'0']++
+				digitPositions[i]++
+				seq = append(seq, int(c-'0'))
+			case strings.ContainsRune(Uppers, c):
+				upperPositions[letter]++
+				letter++
+			default:
+				letter++
+			}
+		}
+	}
+
+	checkUniform(t, "digits", digits)
+	checkUniform(t, "digit positions", digitPositions)
+	checkUniform(t, "uppercase letter positions", upperPositions)
+	checkSequence(t, "digits", seq)
+}
+
+// TestUniformityMemorable checks that the words of passphrases are uniform over the wordlist and
+// their slots, and that the appended digit is uniform in value and word.
+func TestUniformityMemorable(t *testing.T) {
+	const words = 6
+	const bins = 32 // The 7776 words are split into bins of 243 words.
+	config := PasswordConfig{Type: "memorable", Passphrase: PassphraseConfig{Separator: " ", AddDigit: true}}
+
+	pg, err := New(words, config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	indexes := make(map[string]int, len(Wordlist))
+	for i, word := range Wordlist {
+		indexes[word] = i
+	}
+
+	counts := make([]int, len(Wordlist))
+	slots := make([][]int, words)
+	for i := range slots {
+		slots[i] = make([]int, bins)
+	}
+	digits := make([]int, len(Digits))
+	digitSlots := make([]int, words)
+	var seq []int
+
+	for n := 0; n < 10000; n++ {
+		passphrase, err := pg.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		for i, word := range strings.Split(passphrase, " ") {
+			if last := word[len(word)-1]; strings.IndexByte(Digits, last) >= 0 {
+				digits[last-'0']++
+				digitSlots[i]++
+				word = word[:len(word)-1]
+			}
+
+			index, ok := indexes[word]
+			if !ok {
+				t.Fatalf("Generate() = %q, %q is not in the wordlist", passphrase, word)
+			}
+			counts[index]++
+			slots[i][index*bins/len(Wordlist)]++
+			seq = append(seq, index)
+		}
+	}
+
+	checkUniform(t, "words", counts)
+	for i, slot := range slots {
+		checkUniform(t, "word "+strconv.Itoa(i), slot)
+	}
+	checkUniform(t, "digits", digits)
+	checkUniform(t, "digit words", digitSlots)
+	checkSequence(t, "words", seq)
+}
@@ -0,0 +1,50 @@
+package gofee
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits of WPA passphrases and SSIDs.
+const (
+	WPAMinLength  = 8
+	WPAMaxLength  = 63
+	SSIDMaxLength = 32
+)
+
+// wifiEscaper escapes the special characters of the fields of a Wi-Fi payload.
+var wifiEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `:`, `\:`, `"`, `\"`)
+
+// WiFiPayload returns the payload of a Wi-Fi QR code for a WPA network, such as
+// WIFI:T:WPA;S:guest;P:secret12;; which phones read to join the network.
+// It returns an error if the SSID or the passphrase are not valid for WPA: passphrases
+// have 8 to 63 printable ASCII characters, SSIDs up to 32 bytes.
+func WiFiPayload(ssid, password string, hidden bool) (string, error) {
+	if ssid == "" || len(ssid) > SSIDMaxLength {
+		return "", fmt.Errorf("SSID must have 1 to %d bytes, but has %d", SSIDMaxLength, len(ssid))
+	}
+	if len(password) < WPAMinLength || len(password) > WPAMaxLength {
+		return "", fmt.Errorf("WPA passphrase must have %d to %d characters, but has %d", WPAMinLength, WPAMaxLength, len(password))
+	}
+	for _, c := range password {
+		if c < ' ' || c > '~' {
+			return "", fmt.Errorf("WPA passphrase must consist of printable ASCII characters, but contains %q", c)
+		}
+	}
+
+	payload := "WIFI:T:WPA;S:" + wifiField(ssid) + ";P:" + wifiField(password) + ";"
+	if hidden {
+		payload += "H:true;"
+	}
+	return payload + ";", nil
+}
+
+// wifiField escapes a field of a Wi-Fi payload. Fields which could be read as hexadecimal
+// are quoted, so they are taken as text.
+func wifiField(s string) string {
+	s = wifiEscaper.Replace(s)
+	if len(s)%2 == 0 && strings.Trim(s, "0123456789abcdefABCDEF") == "" {
+		return `"` + s + `"`
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package gofee
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestWiFiPayload checks the payloads and the escaping of their fields.
+func TestWiFiPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		ssid     string
+		password string
+		hidden   bool
+		want     string
+	}{
+		{"Plain", "guest", "secret12", false, "WIFI:T:WPA;S:guest;P:secret12;;"},
+		{"Hidden", "guest", "secret12", true, "WIFI:T:WPA;S:guest;P:secret12;H:true;;"},
+		{"Special characters", `Café; "Bar"`, `a\b;c,d:e"f`, false, `WIFI:T:WPA;S:Café\; \"Bar\";P:a\\b\;c\,d\:e\"f;;`},
+		{"Hexadecimal", "CAFE", "0123456789", false, `WIFI:T:WPA;S:"CAFE";P:"0123456789";;`},
+		{"Odd hexadecimal", "ABC", "012345678", false, `WIFI:T:WPA;S:ABC;P:012345678;;`},
+		{"Longest passphrase", "guest", strings.Repeat("x", 63), false, "WIFI:T:WPA;S:guest;P:" + strings.Repeat("x", 63) + ";;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WiFiPayload(tt.ssid, tt.password, tt.hidden)
+			if err != nil {
+				t.Fatalf("WiFiPayload() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("WiFiPayload() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWiFiPayloadErrors checks that invalid SSIDs and passphrases are rejected.
+func TestWiFiPayloadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		ssid     string
+		password string
+		wantErr  string
+	}{
+		{"Empty SSID", "", "secret12", "SSID"},
+		{"Long SSID", strings.Repeat("s", 33), "secret12", "SSID"},
+		{"Short passphrase", "guest", "secret1", "8 to 63"},
+		{"Long passphrase", "guest", strings.Repeat("x", 64), "8 to 63"},
+		{"Non-ASCII passphrase", "guest", "sécret12", "printable ASCII"},
+		{"Control character", "guest", "secret\t12", "printable ASCII"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := WiFiPayload(tt.ssid, tt.password, false)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("WiFiPayload() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package gofee
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// effLargeWordlist holds the EFF large wordlist in its original diceware format,
+// where each line consists of the dice roll, a tab and the word.
+// See https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases.
+//
+//go:embed eff_large_wordlist.txt
+var effLargeWordlist string
+
+// Wordlist contains the words used to generate passphrases.
+// It is parsed from the embedded EFF large wordlist and holds 7776 unique words.
+var Wordlist = parseWordlist(effLargeWordlist)
+
+// parseWordlist extracts the words from a diceware formatted wordlist.
+// Lines without a dice roll are taken as words as they are, empty lines are skipped.
+func parseWordlist(list string) []string {
+	words := make([]string, 0, 7776)
+
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		// Strip the dice roll in front of the word, if there is one.
+		if _, word, found := strings.Cut(line, "\t"); found {
+			line = word
+		}
+
+		words = append(words, line)
+	}
+
+	return words
+}
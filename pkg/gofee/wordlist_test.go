@@ -0,0 +1,53 @@
+package gofee
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestWordlist checks that the embedded EFF wordlist is parsed into 7776 unique words.
+func TestWordlist(t *testing.T) {
+	if len(Wordlist) != 7776 {
+		t.Fatalf("Wordlist has %d words, want 7776", len(Wordlist))
+	}
+
+	seen := make(map[string]struct{}, len(Wordlist))
+	for _, word := range Wordlist {
+		if _, exists := seen[word]; exists {
+			t.Errorf("Duplicate word found: %s", word)
+		}
+		seen[word] = struct{}{}
+	}
+
+	if Wordlist[0] != "abacus" || Wordlist[len(Wordlist)-1] != "zoom" {
+		t.Errorf("Wordlist = [%s ... %s], want [abacus ... zoom]", Wordlist[0], Wordlist[len(Wordlist)-1])
+	}
+}
+
+// TestParseWordlist tests the parsing of diceware formatted wordlists.
+func TestParseWordlist(t *testing.T) {
+	tests := []struct {
+		name string
+		list string
+		want []string
+	}{
+		{
+			name: "Diceware format",
+			list: "11111\tabacus\n11112\tabdomen\n",
+			want: []string{"abacus", "abdomen"},
+		},
+		{
+			name: "Plain words and blank lines",
+			list: "apple\n\n  banana  \r\n",
+			want: []string{"apple", "banana"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseWordlist(tt.list); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseWordlist() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}